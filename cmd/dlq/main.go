@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go1/config"
+	"go1/pkg/kafka"
+	"go1/pkg/logger"
+)
+
+const usage = `Usage: dlq <command> -topic <name>.dlq [flags]
+
+Commands:
+  list    Print DLQ messages with their headers and error reasons (JSON lines)
+  replay  Republish selected DLQ messages to their original topic with x-attempt reset
+
+Flags:
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	topic := fs.String("topic", "", "DLQ topic, e.g. order-domain-events.dlq")
+	key := fs.String("key", "", "only messages with this key")
+	errorContains := fs.String("error", "", "only messages whose DLQ reason contains this text")
+	since := fs.String("since", "", "only messages at or after this time (RFC3339)")
+	until := fs.String("until", "", "only messages at or before this time (RFC3339)")
+	offsets := fs.String("offsets", "", "only these messages, as partition:offset,...")
+	limit := fs.Int("limit", 0, "maximum number of messages (0 = no limit)")
+	all := fs.Bool("all", false, "replay: allow replaying every message matched without other filters")
+	by := fs.String("by", os.Getenv("USER"), "replay: who is replaying, recorded on each message and in the audit topic")
+	_ = fs.Parse(os.Args[2:])
+
+	if *topic == "" || (command != "list" && command != "replay") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// Logs go to stderr; stdout carries the JSON results
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatal("failed to load config", err)
+	}
+
+	filter := kafka.DLQFilter{Key: *key, ErrorContains: *errorContains, Limit: *limit, All: *all}
+	if filter.Since, err = parseTime(*since); err != nil {
+		fatal("invalid -since", err)
+	}
+	if filter.Until, err = parseTime(*until); err != nil {
+		fatal("invalid -until", err)
+	}
+	if filter.Refs, err = kafka.ParseDLQRefs(*offsets); err != nil {
+		fatal("invalid -offsets", err)
+	}
+
+	dlq, err := kafka.NewDLQ(cfg.Kafka, kafka.DLQOptions{
+		DLQTopicSuffix: cfg.Kafka.Retry.DLQSuffix,
+		AuditTopic:     cfg.Kafka.Retry.ReplayAuditTopic,
+	})
+	if err != nil {
+		fatal("failed to connect to Kafka", err)
+	}
+	defer dlq.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out := json.NewEncoder(os.Stdout)
+	switch command {
+	case "list":
+		messages, err := dlq.List(ctx, *topic, filter)
+		if err != nil {
+			fatal("failed to list DLQ", err)
+		}
+		for _, m := range messages {
+			_ = out.Encode(m)
+		}
+	case "replay":
+		replays, err := dlq.Replay(ctx, *topic, filter, *by)
+		for _, r := range replays {
+			_ = out.Encode(r)
+		}
+		if err != nil {
+			fatal("failed to replay DLQ", err)
+		}
+		fmt.Fprintf(os.Stderr, "replayed %d message(s)\n", len(replays))
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func fatal(msg string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
+	os.Exit(1)
+}
@@ -0,0 +1,27 @@
+package config
+
+type JWTClaimsConfig struct {
+	UserID    string `mapstructure:"userId"`    // Defaults to "sub"
+	Role      string `mapstructure:"role"`      // Defaults to "role"
+	Platform  string `mapstructure:"platform"`  // Defaults to "platform"
+	PartnerID string `mapstructure:"partnerId"` // Defaults to "partner_id"
+}
+
+type JWTConfig struct {
+	JWKSFile           string          `mapstructure:"jwksFile"`
+	JWKSURL            string          `mapstructure:"jwksUrl"`
+	JWKSRefreshSeconds int             `mapstructure:"jwksRefreshSeconds"`
+	Issuer             string          `mapstructure:"issuer"`
+	Audience           []string        `mapstructure:"audience"`
+	Algorithms         []string        `mapstructure:"algorithms"` // Allowed: RS256, RS384, RS512, ES256, ES384, ES512
+	ClockSkewSeconds   int             `mapstructure:"clockSkewSeconds"`
+	Claims             JWTClaimsConfig `mapstructure:"claims"`
+}
+
+type AuthConfig struct {
+	// Mode selects how callers are identified:
+	// "jwt"    - verify a Bearer token in the Authorization header (default)
+	// "header" - trust X-User-Id/X-User-Audience headers; only behind a gateway that sets them
+	Mode string    `mapstructure:"mode"`
+	JWT  JWTConfig `mapstructure:"jwt"`
+}
@@ -0,0 +1,6 @@
+package config
+
+type CacheConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	OrderTTLSeconds int  `mapstructure:"orderTtlSeconds"`
+}
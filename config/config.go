@@ -13,6 +13,18 @@ type Config struct {
 	Kafka    KafkaConfig    `mapstructure:"kafka"`
 	Jaeger   JaegerConfig   `mapstructure:"jaeger"`
 	Temporal TemporalConfig `mapstructure:"temporal"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+
+	// Partners maps white-label partner IDs (X-Partner-Id, lowercased) to their settings.
+	// Requests from a partner that is not listed here are rejected.
+	Partners map[string]PartnerConfig `mapstructure:"partners"`
+
+	Outbox        OutboxConfig        `mapstructure:"outbox"`
+	Cache         CacheConfig         `mapstructure:"cache"`
+	Idempotency   IdempotencyConfig   `mapstructure:"idempotency"`
+	RateLimit     RateLimitConfig     `mapstructure:"rateLimit"`
+	WorkerAdmin   WorkerAdminConfig   `mapstructure:"workerAdmin"`
+	WorkerMetrics WorkerMetricsConfig `mapstructure:"workerMetrics"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -40,14 +52,54 @@ func LoadConfig() (*Config, error) {
 	v.SetDefault("kafka.brokers", "localhost:9092")
 	v.SetDefault("jaeger.endpoint", "localhost:4318")
 
+	v.SetDefault("kafka.producer.clientId", "go1")
+	v.SetDefault("kafka.consumer.initialOffset", "newest")
+	v.SetDefault("kafka.consumer.rebalanceStrategy", "roundrobin")
+	v.SetDefault("kafka.consumer.version", "2.6.0")
 	v.SetDefault("kafka.topics.shipment_events", "shipment-events")
 	v.SetDefault("kafka.topics.dispatch_events", "dispatch-events")
 	v.SetDefault("kafka.topics.order_events", "dbserver1.public.orders")
+	v.SetDefault("kafka.topics.order_domain_events", "order-domain-events")
+	v.SetDefault("kafka.retry.replayAuditTopic", "dlq-replays")
+	v.SetDefault("kafka.dedup.ttlHours", 168)
+	v.SetDefault("kafka.dedup.claimTimeoutSeconds", 300)
+	v.SetDefault("kafka.provisioning.defaults.partitions", 3)
+	v.SetDefault("kafka.provisioning.defaults.replicationFactor", 1)
 
 	v.SetDefault("temporal.hostPort", "localhost:7233")
 	v.SetDefault("temporal.namespace", "default")
 	v.SetDefault("temporal.taskQueue", "ORDER_TASK_QUEUE")
 
+	v.SetDefault("auth.mode", "jwt")
+	v.SetDefault("auth.jwt.algorithms", []string{"RS256", "ES256"})
+	v.SetDefault("auth.jwt.clockSkewSeconds", 30)
+	v.SetDefault("auth.jwt.jwksRefreshSeconds", 300)
+	v.SetDefault("auth.jwt.claims.userId", "sub")
+	v.SetDefault("auth.jwt.claims.role", "role")
+	v.SetDefault("auth.jwt.claims.platform", "platform")
+	v.SetDefault("auth.jwt.claims.partnerId", "partner_id")
+
+	v.SetDefault("outbox.pollIntervalMs", 1000)
+	v.SetDefault("outbox.batchSize", 100)
+	v.SetDefault("outbox.retentionHours", 72)
+
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("cache.orderTtlSeconds", 300)
+
+	v.SetDefault("idempotency.enabled", true)
+	v.SetDefault("idempotency.ttlSeconds", 86400)
+	v.SetDefault("idempotency.lockTimeoutSeconds", 30)
+
+	v.SetDefault("rateLimit.enabled", false)
+	v.SetDefault("rateLimit.default.requests", 100)
+	v.SetDefault("rateLimit.default.windowSeconds", 60)
+
+	v.SetDefault("workerAdmin.enabled", false)
+	v.SetDefault("workerAdmin.port", "8081")
+
+	v.SetDefault("workerMetrics.enabled", true)
+	v.SetDefault("workerMetrics.port", "9091")
+
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
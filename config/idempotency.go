@@ -0,0 +1,7 @@
+package config
+
+type IdempotencyConfig struct {
+	Enabled            bool `mapstructure:"enabled"`
+	TTLSeconds         int  `mapstructure:"ttlSeconds"`         // How long a completed response is replayed
+	LockTimeoutSeconds int  `mapstructure:"lockTimeoutSeconds"` // How long an in-flight request holds the key
+}
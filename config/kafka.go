@@ -3,39 +3,119 @@ package config
 type TopicRetryConfig struct {
 	EnableRetry bool `mapstructure:"enableRetry"`
 	MaxAttempts int  `mapstructure:"maxAttempts"`
-	BackoffMs   int  `mapstructure:"backoffMs"`
+	BackoffMs   int  `mapstructure:"backoffMs"` // Delay before the retry topic redelivers a failed message
 }
 
 type KafkaRetryConfig struct {
 	RetrySuffix string                      `mapstructure:"retrySuffix"`
 	DLQSuffix   string                      `mapstructure:"dlqSuffix"`
 	Topics      map[string]TopicRetryConfig `mapstructure:"topics"`
+
+	ReplayAuditTopic string `mapstructure:"replayAuditTopic"` // Records who replayed which DLQ messages
+}
+
+type KafkaAsyncProducerConfig struct {
+	Enabled     bool `mapstructure:"enabled"`
+	LingerMs    int  `mapstructure:"lingerMs"`    // Max time a message waits for its batch to fill
+	BatchSize   int  `mapstructure:"batchSize"`   // Send a batch once it holds this many messages
+	BatchBytes  int  `mapstructure:"batchBytes"`  // Send a batch once it holds this many bytes
+	MaxInFlight int  `mapstructure:"maxInFlight"` // Undelivered messages buffered before PublishAsync blocks
 }
 
 type KafkaProducerConfig struct {
-	RequiredAcks    string `mapstructure:"requiredAcks"` // "all", "local", "none"
-	RetryMax        int    `mapstructure:"retryMax"`
-	Compression     string `mapstructure:"compression"` // "none", "gzip", "snappy", "lz4", "zstd"
-	MaxMessageBytes int    `mapstructure:"maxMessageBytes"`
+	ClientID        string                   `mapstructure:"clientId"`     // Also recorded as the producer of published events
+	RequiredAcks    string                   `mapstructure:"requiredAcks"` // "all", "local", "none"
+	RetryMax        int                      `mapstructure:"retryMax"`
+	Compression     string                   `mapstructure:"compression"` // "none", "gzip", "snappy", "lz4", "zstd"
+	MaxMessageBytes int                      `mapstructure:"maxMessageBytes"`
+	Async           KafkaAsyncProducerConfig `mapstructure:"async"`
+	Idempotent      bool                     `mapstructure:"idempotent"`      // Brokers drop duplicates caused by producer retries; forces requiredAcks all
+	TransactionalID string                   `mapstructure:"transactionalId"` // Makes only the worker's retry/DLQ republish transactional; suffixed with group and host
 }
 
 type KafkaConsumerConfig struct {
 	SessionTimeoutMs    int `mapstructure:"sessionTimeoutMs"`
 	HeartbeatIntervalMs int `mapstructure:"heartbeatIntervalMs"`
 	MaxProcessingTimeMs int `mapstructure:"maxProcessingTimeMs"`
+	KeyConcurrency      int `mapstructure:"keyConcurrency"` // Workers per partition, hashed by key; 0 or 1 = sequential
+
+	InitialOffset     string `mapstructure:"initialOffset"`     // "newest" or "oldest": where a group without committed offsets starts
+	RebalanceStrategy string `mapstructure:"rebalanceStrategy"` // "roundrobin", "range", "sticky"
+	Version           string `mapstructure:"version"`           // Kafka protocol version, e.g. "2.6.0"
+	FetchMinBytes     int    `mapstructure:"fetchMinBytes"`
+	FetchDefaultBytes int    `mapstructure:"fetchDefaultBytes"`
+	FetchMaxBytes     int    `mapstructure:"fetchMaxBytes"`  // 0 = no limit; must exceed the largest message
+	FetchMaxWaitMs    int    `mapstructure:"fetchMaxWaitMs"` // How long the broker waits for fetchMinBytes
+	IsolationLevel    string `mapstructure:"isolationLevel"` // "read_uncommitted" or "read_committed"; defaults to read_committed with a transactionalId
+	InstanceID        string `mapstructure:"instanceId"`     // Static membership: stable and unique per instance, e.g. the pod name
+}
+
+type KafkaCloudEventsConfig struct {
+	Source string            `mapstructure:"source"` // ce_source attribute, defaults to the producer clientId
+	Topics map[string]string `mapstructure:"topics"` // Topic -> content mode: "binary" or "structured"
+}
+
+type KafkaDedupConfig struct {
+	Store               string `mapstructure:"store"`               // "redis", "postgres" or empty to disable
+	TTLHours            int    `mapstructure:"ttlHours"`            // How long processed message IDs are remembered
+	ClaimTimeoutSeconds int    `mapstructure:"claimTimeoutSeconds"` // How long a message being handled blocks its redeliveries
+}
+
+type KafkaSchemaRegistryConfig struct {
+	File string `mapstructure:"file"` // Local protobuf schema registry (JSON); empty disables schema validation
 }
 
 type KafkaTopicsConfig struct {
-	ShipmentEvents string `mapstructure:"shipment_events"`
-	DispatchEvents string `mapstructure:"dispatch_events"`
-	OrderEvents    string `mapstructure:"order_events"`
+	ShipmentEvents    string `mapstructure:"shipment_events"`
+	DispatchEvents    string `mapstructure:"dispatch_events"`
+	OrderEvents       string `mapstructure:"order_events"`        // Debezium CDC stream of the orders table
+	OrderDomainEvents string `mapstructure:"order_domain_events"` // Versioned order domain events relayed from the outbox
 }
 
 type KafkaConfig struct {
 	Brokers  string              `mapstructure:"brokers"`
+	TLS      KafkaTLSConfig      `mapstructure:"tls"`
+	SASL     KafkaSASLConfig     `mapstructure:"sasl"`
 	GroupID  string              `mapstructure:"groupId"`
 	Producer KafkaProducerConfig `mapstructure:"producer"`
 	Consumer KafkaConsumerConfig `mapstructure:"consumer"`
 	Retry    KafkaRetryConfig    `mapstructure:"retry"`
 	Topics   KafkaTopicsConfig   `mapstructure:"topics"`
+
+	// StrictEventTopics must carry the event envelope (id, type); other event topics also
+	// accept plain JSON payloads from producers that have not migrated yet
+	StrictEventTopics []string `mapstructure:"strictEventTopics"`
+
+	CloudEvents    KafkaCloudEventsConfig    `mapstructure:"cloudEvents"`
+	SchemaRegistry KafkaSchemaRegistryConfig `mapstructure:"schemaRegistry"`
+	Dedup          KafkaDedupConfig          `mapstructure:"dedup"`
+	Provisioning   KafkaProvisioningConfig   `mapstructure:"provisioning"`
+}
+
+type KafkaTopicSpec struct {
+	Partitions        int    `mapstructure:"partitions"`
+	ReplicationFactor int    `mapstructure:"replicationFactor"`
+	RetentionMs       int64  `mapstructure:"retentionMs"`   // 0 keeps the broker default
+	CleanupPolicy     string `mapstructure:"cleanupPolicy"` // "delete", "compact", "compact,delete"; empty keeps the broker default
+}
+
+type KafkaProvisioningConfig struct {
+	Enabled  bool           `mapstructure:"enabled"`
+	Defaults KafkaTopicSpec `mapstructure:"defaults"`
+	// Topics overrides the defaults per topic, including retry and DLQ topics (e.g. "dispatch-events.dlq")
+	Topics map[string]KafkaTopicSpec `mapstructure:"topics"`
+}
+
+type KafkaTLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"caFile"`             // PEM CA bundle; empty uses the system roots
+	CertFile           string `mapstructure:"certFile"`           // PEM client certificate for mutual TLS
+	KeyFile            string `mapstructure:"keyFile"`            // PEM client key for mutual TLS
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"` // Local testing only
+}
+
+type KafkaSASLConfig struct {
+	Mechanism string `mapstructure:"mechanism"` // "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512"; empty disables SASL
+	Username  string `mapstructure:"username"`
+	Password  string `mapstructure:"password"`
 }
@@ -0,0 +1,7 @@
+package config
+
+type OutboxConfig struct {
+	PollIntervalMs int `mapstructure:"pollIntervalMs"`
+	BatchSize      int `mapstructure:"batchSize"`
+	RetentionHours int `mapstructure:"retentionHours"` // 0 keeps published events forever
+}
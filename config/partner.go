@@ -0,0 +1,7 @@
+package config
+
+type PartnerConfig struct {
+	Name                string   `mapstructure:"name"`
+	EnabledServiceTypes []string `mapstructure:"enabledServiceTypes"` // Empty means all service types
+	MaxPointsPerOrder   int      `mapstructure:"maxPointsPerOrder"`   // 0 means unlimited
+}
@@ -0,0 +1,19 @@
+package config
+
+type RateLimitRule struct {
+	Requests      int `mapstructure:"requests"`
+	WindowSeconds int `mapstructure:"windowSeconds"`
+}
+
+type RouteRateLimitConfig struct {
+	Method        string `mapstructure:"method"`
+	Path          string `mapstructure:"path"` // Gin route pattern, e.g. /orders/:id
+	Requests      int    `mapstructure:"requests"`
+	WindowSeconds int    `mapstructure:"windowSeconds"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool                   `mapstructure:"enabled"`
+	Default RateLimitRule          `mapstructure:"default"`
+	Routes  []RouteRateLimitConfig `mapstructure:"routes"`
+}
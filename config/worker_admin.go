@@ -0,0 +1,7 @@
+package config
+
+type WorkerAdminConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	Port    string            `mapstructure:"port"`
+	Tokens  map[string]string `mapstructure:"tokens"` // Operator name -> bearer token; required when enabled, the name is recorded on DLQ replays
+}
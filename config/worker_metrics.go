@@ -0,0 +1,6 @@
+package config
+
+type WorkerMetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Port    string `mapstructure:"port"` // Serves Prometheus metrics on GET /metrics
+}
@@ -26,11 +26,13 @@ func (r *CreateOrderRequest) Validate() error {
 }
 
 type OrderPointRequest struct {
-	Lat     float64 `json:"lat" binding:"required"`
-	Lng     float64 `json:"lng" binding:"required"`
-	Type    string  `json:"type" binding:"required"` // 'pickup', 'dropoff'
-	Address string  `json:"address"`
-	Phone   string  `json:"phone"`
+	Lat         float64 `json:"lat" binding:"required"`
+	Lng         float64 `json:"lng" binding:"required"`
+	Type        string  `json:"type" binding:"required"` // 'pickup', 'dropoff'
+	Address     string  `json:"address"`
+	ContactName string  `json:"contact_name"`
+	Phone       string  `json:"phone"`
+	Notes       string  `json:"notes"`
 }
 
 type OrderResponse struct {
@@ -31,11 +31,13 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	var points []application.OrderPointInput
 	for _, p := range req.Points {
 		points = append(points, application.OrderPointInput{
-			Lat:     p.Lat,
-			Lng:     p.Lng,
-			Type:    p.Type,
-			Address: p.Address,
-			Phone:   p.Phone,
+			Lat:         p.Lat,
+			Lng:         p.Lng,
+			Type:        p.Type,
+			Address:     p.Address,
+			ContactName: p.ContactName,
+			Phone:       p.Phone,
+			Notes:       p.Notes,
 		})
 	}
 
@@ -1,19 +1,63 @@
 package middleware
 
 import (
+	"fmt"
+	"strings"
+
+	"go1/config"
+	"go1/pkg/auth"
+	"go1/pkg/logger"
 	"go1/pkg/request"
 	"go1/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(bypass bool) gin.HandlerFunc {
+const (
+	AuthModeJWT    = "jwt"
+	AuthModeHeader = "header"
+)
+
+// NewAuthMiddleware builds the auth middleware for the configured mode.
+// There is no bypass: header mode must be selected explicitly and is only safe
+// behind a trusted gateway that strips and sets the X-User-* headers.
+func NewAuthMiddleware(cfg config.AuthConfig) (gin.HandlerFunc, error) {
+	switch cfg.Mode {
+	case AuthModeJWT, "":
+		verifier, err := auth.NewJWTVerifier(cfg.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize JWT auth: %w", err)
+		}
+		return JWTAuthMiddleware(verifier), nil
+	case AuthModeHeader:
+		logger.Log.Warn("Auth running in header mode: X-User-* headers are trusted as-is")
+		return HeaderAuthMiddleware(), nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode: %s", cfg.Mode)
+	}
+}
+
+// JWTAuthMiddleware populates the user context from a verified Bearer token.
+func JWTAuthMiddleware(verifier *auth.JWTVerifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if bypass {
-			c.Next()
+		token := auth.BearerToken(c.GetHeader("Authorization"))
+		user, err := verifier.Verify(c.Request.Context(), token)
+		if err != nil {
+			logger.Log.Debug("Rejected token", logger.Field{Key: "error", Value: err})
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(401, gin.H{"error": "Unauthorized: valid bearer token required"})
+			c.Abort()
 			return
 		}
 
+		setUser(c, user)
+		c.Next()
+	}
+}
+
+// HeaderAuthMiddleware trusts identity headers set by an upstream gateway.
+func HeaderAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		userID := c.GetHeader(string(utils.XUserIDHeader))
 		if userID == "" {
 			c.JSON(401, gin.H{"error": "Unauthorized: X-User-Id required"})
@@ -28,9 +72,15 @@ func AuthMiddleware(bypass bool) gin.HandlerFunc {
 			PartnerID: c.GetHeader(string(utils.XPartnerIDHeader)),
 		}
 
-		ctx := request.WithUser(c.Request.Context(), user)
-		c.Request = c.Request.WithContext(ctx)
-
+		setUser(c, user)
 		c.Next()
 	}
 }
+
+// setUser stores the authenticated user on the request context. Partner IDs are lowercased
+// here once, matching the partner config keys, so they are compared and stored consistently.
+func setUser(c *gin.Context, user *request.UserContext) {
+	user.PartnerID = strings.ToLower(strings.TrimSpace(user.PartnerID))
+	ctx := request.WithUser(c.Request.Context(), user)
+	c.Request = c.Request.WithContext(ctx)
+}
@@ -14,6 +14,7 @@ import (
 	"go1/pkg/postgres"
 	"go1/pkg/redis"
 
+	"github.com/gin-gonic/gin"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
@@ -24,6 +25,7 @@ type Server struct {
 	kafka          *kafka.KafkaProducer
 	config         *config.Config
 	tracerProvider *sdktrace.TracerProvider
+	authMiddleware gin.HandlerFunc
 }
 
 func New(cfg *config.Config) (*Server, error) {
@@ -44,6 +46,9 @@ func New(cfg *config.Config) (*Server, error) {
 	if err := s.initKafka(); err != nil {
 		return nil, err
 	}
+	if err := s.initAuth(); err != nil {
+		return nil, err
+	}
 
 	s.initHTTPServer()
 
@@ -2,6 +2,7 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	apiMiddleware "go1/internal/api/middleware"
 	"go1/internal/shared/order"
@@ -61,14 +62,36 @@ func (s *Server) initRedis() error {
 }
 
 func (s *Server) initKafka() error {
-	kf, err := kafka.NewProducer(s.config.Kafka.Brokers, s.config.Kafka.Producer)
+	kf, err := kafka.NewProducer(s.config.Kafka)
 	if err != nil {
 		return err
 	}
+	if err := kf.UseCloudEvents(s.config.Kafka.CloudEvents); err != nil {
+		kf.Close()
+		return err
+	}
+	if s.config.Kafka.SchemaRegistry.File != "" {
+		schemas, err := kafka.LoadSchemaRegistry(s.config.Kafka.SchemaRegistry.File)
+		if err != nil {
+			kf.Close()
+			return err
+		}
+		kf.UseSchemaRegistry(schemas)
+	}
 	s.kafka = kf
 	return nil
 }
 
+func (s *Server) initAuth() error {
+	authMiddleware, err := apiMiddleware.NewAuthMiddleware(s.config.Auth)
+	if err != nil {
+		return err
+	}
+	s.authMiddleware = authMiddleware
+	logger.Log.Info("Auth initialized", logger.Field{Key: "mode", Value: s.config.Auth.Mode})
+	return nil
+}
+
 func (s *Server) initHTTPServer() {
 	if s.config.App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -79,15 +102,25 @@ func (s *Server) initHTTPServer() {
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 	router.Use(middleware.CORSMiddleware())
-	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.MetricsMiddleware(s.config.Partners))
 	router.Use(middleware.TracingMiddleware(s.config.App.Name))
-	router.Use(apiMiddleware.AuthMiddleware(false)) // Auth enabled
+	router.Use(s.authMiddleware)
+	if s.config.RateLimit.Enabled {
+		router.Use(middleware.RateLimitMiddleware(s.redis, s.config.RateLimit))
+	}
+	if s.config.Idempotency.Enabled {
+		// Must run after auth: keys are scoped per user
+		router.Use(middleware.IdempotencyMiddleware(s.redis, middleware.IdempotencyOptions{
+			TTL:         time.Duration(s.config.Idempotency.TTLSeconds) * time.Second,
+			LockTimeout: time.Duration(s.config.Idempotency.LockTimeoutSeconds) * time.Second,
+		}))
+	}
 
 	// Prometheus metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Initialize Modules
-	order.Init(router, s.postgres.Pool)
+	order.Init(router, s.postgres.Pool, s.redis, s.config)
 
 	s.httpServer = &http.Server{
 		Addr:    ":" + s.config.App.Port,
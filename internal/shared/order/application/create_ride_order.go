@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/metrics"
 	"go1/pkg/request"
 )
 
@@ -13,8 +14,6 @@ func (s *orderService) CreateRideOrder(ctx context.Context, input CreateRideOrde
 	if !ok {
 		return nil, fmt.Errorf("internal: user context missing")
 	}
-	fmt.Println("-->userCtx: ", userCtx.Role)
-	fmt.Println("-->input: ", input)
 	customerID, driverID, err := s.resolveParticipants(userCtx.Role, userCtx.UserID, input.CustomerID, input.DriverID)
 
 	if err != nil {
@@ -26,6 +25,11 @@ func (s *orderService) CreateRideOrder(ctx context.Context, input CreateRideOrde
 		entity.CustomerVO{ID: customerID},
 		entity.DriverVO{ID: driverID},
 	)
+	order.SetPartner(userCtx.PartnerID)
+
+	if err := s.authorize(ctx, order, OrderActionCreate); err != nil {
+		return nil, err
+	}
 
 	if err := s.enrichData(order, ctx, input); err != nil {
 		return nil, err
@@ -44,8 +48,9 @@ func (s *orderService) CreateRideOrder(ctx context.Context, input CreateRideOrde
 	if err := s.repo.Create(ctx, order); err != nil {
 		return nil, err
 	}
+	metrics.OrdersCreatedTotal.WithLabelValues(order.PartnerID, order.Service.Type).Inc()
 
-	return s.mapper.ToOrderOutput(order), nil
+	return s.mapper.ToOrderOutputForViewer(order, userCtx), nil
 }
 
 func (s *orderService) enrichData(order *entity.RideOrderEntity, ctx context.Context, input CreateRideOrderInput) error {
@@ -64,11 +69,14 @@ func (s *orderService) enrichData(order *entity.RideOrderEntity, ctx context.Con
 	var points []entity.PointVO
 	for i, p := range input.Points {
 		points = append(points, entity.PointVO{
-			Lat:     p.Lat,
-			Lng:     p.Lng,
-			Address: p.Address,
-			Type:    p.Type,
-			Order:   i,
+			Lat:         p.Lat,
+			Lng:         p.Lng,
+			Address:     p.Address,
+			Type:        p.Type,
+			Order:       i,
+			ContactName: p.ContactName,
+			Phone:       p.Phone,
+			Notes:       p.Notes,
 		})
 	}
 
@@ -16,11 +16,13 @@ type CreateRideOrderInput struct {
 }
 
 type OrderPointInput struct {
-	Lat     float64 `json:"lat"`
-	Lng     float64 `json:"lng"`
-	Type    string  `json:"type"` // 'pickup' | 'dropoff'
-	Address string  `json:"address"`
-	Phone   string  `json:"phone"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Type        string  `json:"type"` // 'pickup' | 'dropoff'
+	Address     string  `json:"address"`
+	ContactName string  `json:"contact_name"`
+	Phone       string  `json:"phone"`
+	Notes       string  `json:"notes"`
 }
 
 type OrderOutput struct {
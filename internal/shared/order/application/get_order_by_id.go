@@ -2,13 +2,16 @@ package application
 
 import (
 	"context"
+
+	"go1/pkg/request"
 )
 
 func (s *orderService) GetByID(ctx context.Context, id string) (*OrderOutput, error) {
-	order, err := s.repo.GetByID(ctx, id)
+	order, err := s.getOrder(ctx, id, OrderActionView)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.mapper.ToOrderOutput(order), nil
-}
\ No newline at end of file
+	userCtx, _ := request.UserFromContext(ctx)
+	return s.mapper.ToOrderOutputForViewer(order, userCtx), nil
+}
@@ -2,9 +2,17 @@ package application
 
 import (
 	"context"
+
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/request"
 )
 
 type OrderService interface {
 	CreateRideOrder(ctx context.Context, input CreateRideOrderInput) (*OrderOutput, error)
 	GetByID(ctx context.Context, id string) (*OrderOutput, error)
 }
+
+// OrderPolicy decides whether a caller may perform an action on an order
+type OrderPolicy interface {
+	Authorize(user *request.UserContext, order *entity.RideOrderEntity, action OrderAction) bool
+}
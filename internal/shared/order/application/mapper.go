@@ -1,6 +1,10 @@
 package application
 
-import "go1/internal/shared/order/domain/entity"
+import (
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+)
 
 type OrderMapper struct {
 }
@@ -29,3 +33,32 @@ func (m *OrderMapper) ToOrderOutput(order *entity.RideOrderEntity) *OrderOutput
 		UpdatedAt:  order.UpdatedAt,
 	}
 }
+
+// ToOrderOutputForViewer maps the order and masks point contact details
+// (name, phone, notes) when the viewer is not allowed to see them.
+func (m *OrderMapper) ToOrderOutputForViewer(order *entity.RideOrderEntity, viewer *request.UserContext) *OrderOutput {
+	output := m.ToOrderOutput(order)
+	if output == nil || canViewPointContacts(viewer) {
+		return output
+	}
+
+	points := make([]entity.PointVO, len(output.Points))
+	for i, p := range output.Points {
+		points[i] = p.MaskContact()
+	}
+	output.Points = points
+	return output
+}
+
+// contactViewerRoles are the roles that see point contact details unmasked: admins, and drivers,
+// who need them to reach the pickup and drop-off. Which orders a user may read at all is
+// decided by the order policy.
+var contactViewerRoles = map[utils.UserRole]bool{
+	utils.UserRoleAdmin:  true,
+	utils.UserRoleDriver: true,
+}
+
+// canViewPointContacts reports whether the viewer's role may see point contact details
+func canViewPointContacts(viewer *request.UserContext) bool {
+	return viewer != nil && contactViewerRoles[viewer.Role]
+}
@@ -0,0 +1,60 @@
+package application
+
+import (
+	"testing"
+
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+)
+
+func TestToOrderOutputForViewerMasksContactsByRole(t *testing.T) {
+	point := entity.PointVO{Type: "pickup", ContactName: "Nguyen An", Phone: "0901234567", Notes: "gate 3"}
+	masked := entity.PointVO{Type: "pickup", ContactName: "********n", Phone: "*******567"}
+
+	tests := []struct {
+		name   string
+		viewer *request.UserContext
+		want   entity.PointVO
+	}{
+		{name: "admin", viewer: &request.UserContext{UserID: "a1", Role: utils.UserRoleAdmin}, want: point},
+		{name: "driver", viewer: &request.UserContext{UserID: "d1", Role: utils.UserRoleDriver}, want: point},
+		{name: "customer who created the order", viewer: &request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer}, want: masked},
+		{name: "unknown role", viewer: &request.UserContext{UserID: "x1", Role: "partner_portal"}, want: masked},
+		{name: "no viewer", viewer: nil, want: masked},
+	}
+
+	order := &entity.RideOrderEntity{CreatedBy: "c1", Points: []entity.PointVO{point}}
+	mapper := NewOrderMapper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := mapper.ToOrderOutputForViewer(order, tt.viewer)
+			if got := output.Points[0]; got != tt.want {
+				t.Errorf("point = %+v, want %+v", got, tt.want)
+			}
+			if order.Points[0] != point {
+				t.Errorf("order points were modified: %+v", order.Points[0])
+			}
+		})
+	}
+}
+
+func TestMaskContact(t *testing.T) {
+	tests := []struct {
+		name  string
+		point entity.PointVO
+		want  entity.PointVO
+	}{
+		{name: "empty contact stays empty", point: entity.PointVO{}, want: entity.PointVO{}},
+		{name: "short values fully masked", point: entity.PointVO{ContactName: "A", Phone: "12"}, want: entity.PointVO{ContactName: "*", Phone: "**"}},
+		{name: "multibyte name", point: entity.PointVO{ContactName: "Đức", Phone: "0901234567", Notes: "x"}, want: entity.PointVO{ContactName: "**c", Phone: "*******567"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.point.MaskContact(); got != tt.want {
+				t.Errorf("MaskContact() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
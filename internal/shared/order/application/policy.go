@@ -0,0 +1,52 @@
+package application
+
+import (
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+)
+
+type OrderAction string
+
+const (
+	OrderActionCreate OrderAction = "create"
+	OrderActionView   OrderAction = "view"
+)
+
+type orderPolicyImpl struct{}
+
+func NewOrderPolicy() OrderPolicy {
+	return &orderPolicyImpl{}
+}
+
+// Authorize never crosses partners. Within the same partner, admins may do everything;
+// otherwise the caller must take part in the order: the customer who owns it, the driver
+// assigned to it, or (for reads) the user who created it.
+func (p *orderPolicyImpl) Authorize(user *request.UserContext, order *entity.RideOrderEntity, action OrderAction) bool {
+	if user == nil || order == nil || user.UserID == "" {
+		return false
+	}
+
+	if user.PartnerID != order.PartnerID {
+		return false
+	}
+
+	if user.Role == utils.UserRoleAdmin {
+		return true
+	}
+
+	isCustomer := order.Customer.ID == user.UserID
+	isDriver := order.Driver.ID != "" && order.Driver.ID == user.UserID
+
+	switch action {
+	case OrderActionCreate:
+		if user.Role == utils.UserRoleDriver {
+			return isDriver
+		}
+		return isCustomer
+	case OrderActionView:
+		return isCustomer || isDriver || order.CreatedBy == user.UserID
+	default:
+		return false
+	}
+}
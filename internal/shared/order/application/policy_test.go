@@ -0,0 +1,71 @@
+package application
+
+import (
+	"testing"
+
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+)
+
+func TestOrderPolicyAuthorize(t *testing.T) {
+	order := &entity.RideOrderEntity{
+		ID:        "o1",
+		CreatedBy: "a1",
+		PartnerID: "acme",
+		Customer:  entity.CustomerVO{ID: "c1"},
+		Driver:    entity.DriverVO{ID: "d1"},
+	}
+
+	tests := []struct {
+		name       string
+		user       *request.UserContext
+		wantView   bool
+		wantCreate bool
+	}{
+		{name: "owning customer", user: &request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer, PartnerID: "acme"}, wantView: true, wantCreate: true},
+		{name: "other customer", user: &request.UserContext{UserID: "c2", Role: utils.UserRoleCustomer, PartnerID: "acme"}},
+		{name: "owning customer of another partner", user: &request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer, PartnerID: "other"}},
+		{name: "first-party owning customer", user: &request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer}},
+		{name: "assigned driver", user: &request.UserContext{UserID: "d1", Role: utils.UserRoleDriver, PartnerID: "acme"}, wantView: true, wantCreate: true},
+		{name: "other driver", user: &request.UserContext{UserID: "d2", Role: utils.UserRoleDriver, PartnerID: "acme"}},
+		{name: "assigned driver of another partner", user: &request.UserContext{UserID: "d1", Role: utils.UserRoleDriver, PartnerID: "other"}},
+		{name: "driver who is also the customer", user: &request.UserContext{UserID: "c1", Role: utils.UserRoleDriver, PartnerID: "acme"}, wantView: true},
+		{name: "admin", user: &request.UserContext{UserID: "a2", Role: utils.UserRoleAdmin, PartnerID: "acme"}, wantView: true, wantCreate: true},
+		{name: "admin of another partner", user: &request.UserContext{UserID: "a2", Role: utils.UserRoleAdmin, PartnerID: "other"}},
+		{name: "first-party admin", user: &request.UserContext{UserID: "a2", Role: utils.UserRoleAdmin}},
+		{name: "creator without a role", user: &request.UserContext{UserID: "a1", PartnerID: "acme"}, wantView: true},
+		{name: "empty user id", user: &request.UserContext{Role: utils.UserRoleAdmin, PartnerID: "acme"}},
+		{name: "no user", user: nil},
+	}
+
+	policy := NewOrderPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Authorize(tt.user, order, OrderActionView); got != tt.wantView {
+				t.Errorf("Authorize(view) = %v, want %v", got, tt.wantView)
+			}
+			if got := policy.Authorize(tt.user, order, OrderActionCreate); got != tt.wantCreate {
+				t.Errorf("Authorize(create) = %v, want %v", got, tt.wantCreate)
+			}
+		})
+	}
+}
+
+func TestOrderPolicyAuthorizeFirstPartyOrder(t *testing.T) {
+	order := &entity.RideOrderEntity{ID: "o1", Customer: entity.CustomerVO{ID: "c1"}}
+	policy := NewOrderPolicy()
+
+	if !policy.Authorize(&request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer}, order, OrderActionView) {
+		t.Error("first-party customer denied their own first-party order")
+	}
+	if policy.Authorize(&request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer, PartnerID: "acme"}, order, OrderActionView) {
+		t.Error("partner customer allowed a first-party order")
+	}
+	if policy.Authorize(&request.UserContext{UserID: "d1", Role: utils.UserRoleDriver}, order, OrderActionView) {
+		t.Error("driver allowed an order with no driver assigned")
+	}
+	if policy.Authorize(&request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer}, order, "delete") {
+		t.Error("customer allowed an unknown action")
+	}
+}
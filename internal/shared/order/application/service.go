@@ -2,10 +2,12 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go1/internal/shared/order/domain"
 	"go1/internal/shared/order/domain/entity"
 	"go1/pkg/apperrors"
+	"go1/pkg/request"
 	"go1/pkg/utils"
 )
 
@@ -17,6 +19,7 @@ type orderService struct {
 	paymentGateway  domain.PaymentGateway
 	locationGateway domain.LocationGateway
 	rideValidator   domain.RideOrderValidator
+	policy          OrderPolicy
 }
 
 func NewOrderService(
@@ -27,6 +30,7 @@ func NewOrderService(
 	paymentGateway domain.PaymentGateway,
 	locationGateway domain.LocationGateway,
 	rideValidator domain.RideOrderValidator,
+	policy OrderPolicy,
 ) OrderService {
 	return &orderService{
 		repo:            repo,
@@ -36,9 +40,39 @@ func NewOrderService(
 		paymentGateway:  paymentGateway,
 		locationGateway: locationGateway,
 		rideValidator:   rideValidator,
+		policy:          policy,
 	}
 }
 
+// authorize checks the caller against the order. Denied reads and mutations are reported
+// as not found so callers cannot probe which order IDs exist.
+func (s *orderService) authorize(ctx context.Context, order *entity.RideOrderEntity, action OrderAction) error {
+	user, _ := request.UserFromContext(ctx)
+	if s.policy.Authorize(user, order, action) {
+		return nil
+	}
+	if action == OrderActionCreate {
+		return apperrors.NewForbiddenError("not allowed to create this order")
+	}
+	return apperrors.NewNotFoundError(fmt.Sprintf("order %s not found", order.ID))
+}
+
+// getOrder loads an order and authorizes the caller for action
+func (s *orderService) getOrder(ctx context.Context, id string, action OrderAction) (*entity.RideOrderEntity, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("order %s not found", id))
+		}
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, order, action); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
 func (s *orderService) resolveParticipants(role utils.UserRole, authUserID string, inputCustomerID string, inputDriverID string) (customerID string, driverID string, err error) {
 	switch role {
 	case utils.UserRoleAdmin:
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go1/internal/shared/order/domain"
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/apperrors"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+)
+
+// fakeOrderRepository serves orders from memory
+type fakeOrderRepository struct {
+	domain.OrderRepository
+	orders map[string]*entity.RideOrderEntity
+}
+
+func (r *fakeOrderRepository) GetByID(_ context.Context, id string) (*entity.RideOrderEntity, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func TestGetByIDHidesDeniedOrders(t *testing.T) {
+	repo := &fakeOrderRepository{orders: map[string]*entity.RideOrderEntity{
+		"o1": {ID: "o1", CreatedBy: "c1", PartnerID: "acme", Customer: entity.CustomerVO{ID: "c1"}},
+	}}
+	service := NewOrderService(repo, NewOrderMapper(), nil, nil, nil, nil, nil, NewOrderPolicy())
+
+	owner := request.WithUser(context.Background(), &request.UserContext{UserID: "c1", Role: utils.UserRoleCustomer, PartnerID: "acme"})
+	if output, err := service.GetByID(owner, "o1"); err != nil || output == nil {
+		t.Fatalf("GetByID() by owner = %v, %v", output, err)
+	}
+
+	// Look up an order the caller may not see and one that does not exist, under the same ID
+	stranger := request.WithUser(context.Background(), &request.UserContext{UserID: "c2", Role: utils.UserRoleCustomer, PartnerID: "acme"})
+	_, deniedErr := service.GetByID(stranger, "o1")
+	delete(repo.orders, "o1")
+	_, missingErr := service.GetByID(stranger, "o1")
+
+	var denied, missing *apperrors.AppError
+	if !errors.As(deniedErr, &denied) || !errors.As(missingErr, &missing) {
+		t.Fatalf("errors are not AppErrors: denied = %v, missing = %v", deniedErr, missingErr)
+	}
+	if *denied != *missing {
+		t.Errorf("denied error %+v differs from missing error %+v", denied, missing)
+	}
+	if missing.Status != http.StatusNotFound {
+		t.Errorf("missing order status = %d, want 404", missing.Status)
+	}
+}
@@ -2,19 +2,26 @@ package validator
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go1/config"
 	"go1/internal/shared/order/domain"
 	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/apperrors"
 )
 
-type rideOrderValidatorImpl struct{}
+type rideOrderValidatorImpl struct {
+	partners map[string]config.PartnerConfig
+}
 
-func NewRideOrderValidator() domain.RideOrderValidator {
-	return &rideOrderValidatorImpl{}
+func NewRideOrderValidator(partners map[string]config.PartnerConfig) domain.RideOrderValidator {
+	return &rideOrderValidatorImpl{partners: partners}
 }
 
 func (v *rideOrderValidatorImpl) ValidateCreate(ctx context.Context, order *entity.RideOrderEntity) error {
-
-	return nil
+	return v.ValidatePartner(ctx, order)
 }
 
 func (v *rideOrderValidatorImpl) ValidatePoints(ctx context.Context, order *entity.RideOrderEntity) error {
@@ -23,3 +30,37 @@ func (v *rideOrderValidatorImpl) ValidatePoints(ctx context.Context, order *enti
 	}
 	return nil
 }
+
+// ValidatePartner applies the per-partner settings: unknown partners are rejected,
+// and a partner can only order its enabled service types within its limits.
+func (v *rideOrderValidatorImpl) ValidatePartner(ctx context.Context, order *entity.RideOrderEntity) error {
+	if order.PartnerID == "" {
+		return nil
+	}
+
+	// Viper lowercases map keys; the auth middleware lowercases partner IDs to match
+	partner, ok := v.partners[order.PartnerID]
+	if !ok {
+		return apperrors.NewForbiddenError(fmt.Sprintf("partner %s is not enabled", order.PartnerID))
+	}
+
+	if len(partner.EnabledServiceTypes) > 0 {
+		enabled := false
+		for _, t := range partner.EnabledServiceTypes {
+			if strings.EqualFold(t, order.Service.Type) {
+				enabled = true
+				break
+			}
+		}
+		if !enabled {
+			return apperrors.NewServiceDisabledError(fmt.Sprintf("service type %s is not enabled for partner %s", order.Service.Type, order.PartnerID))
+		}
+	}
+
+	if partner.MaxPointsPerOrder > 0 && len(order.GetPoints()) > partner.MaxPointsPerOrder {
+		return apperrors.NewAppError(http.StatusBadRequest,
+			fmt.Sprintf("at most %d points are allowed per order", partner.MaxPointsPerOrder), http.StatusBadRequest)
+	}
+
+	return nil
+}
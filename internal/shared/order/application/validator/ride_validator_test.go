@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go1/config"
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/apperrors"
+)
+
+func TestValidatePartner(t *testing.T) {
+	partners := map[string]config.PartnerConfig{
+		"acme":  {EnabledServiceTypes: []string{"bike"}, MaxPointsPerOrder: 2},
+		"globo": {},
+	}
+	points := func(n int) []entity.PointVO { return make([]entity.PointVO, n) }
+
+	tests := []struct {
+		name       string
+		order      *entity.RideOrderEntity
+		wantStatus int // 0 means valid
+	}{
+		{name: "first-party order", order: &entity.RideOrderEntity{Points: points(5)}},
+		{name: "unknown partner", order: &entity.RideOrderEntity{PartnerID: "other"}, wantStatus: http.StatusForbidden},
+		{name: "partner IDs are matched exactly", order: &entity.RideOrderEntity{PartnerID: "ACME"}, wantStatus: http.StatusForbidden},
+		{name: "enabled service type", order: &entity.RideOrderEntity{PartnerID: "acme", Service: entity.ServiceVO{Type: "BIKE"}, Points: points(2)}},
+		{name: "disabled service type", order: &entity.RideOrderEntity{PartnerID: "acme", Service: entity.ServiceVO{Type: "car"}}, wantStatus: http.StatusBadRequest},
+		{name: "too many points", order: &entity.RideOrderEntity{PartnerID: "acme", Service: entity.ServiceVO{Type: "bike"}, Points: points(3)}, wantStatus: http.StatusBadRequest},
+		{name: "no partner limits", order: &entity.RideOrderEntity{PartnerID: "globo", Service: entity.ServiceVO{Type: "car"}, Points: points(10)}},
+	}
+
+	v := NewRideOrderValidator(partners)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateCreate(context.Background(), tt.order)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("ValidateCreate() error = %v, want nil", err)
+				}
+				return
+			}
+			var appErr *apperrors.AppError
+			if !errors.As(err, &appErr) || appErr.Status != tt.wantStatus {
+				t.Fatalf("ValidateCreate() error = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"context"
+	"go1/internal/shared/order/domain/entity"
+)
+
+// OrderCache defines the contract for the order read cache
+type OrderCache interface {
+	// Get returns nil without error on a cache miss
+	Get(ctx context.Context, id string) (*entity.RideOrderEntity, error)
+	Set(ctx context.Context, order *entity.RideOrderEntity) error
+	Invalidate(ctx context.Context, id string) error
+}
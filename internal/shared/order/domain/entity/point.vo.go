@@ -1,10 +1,45 @@
 package entity
 
+import "strings"
+
 // Point Value Object
 type PointVO struct {
-	Lat     float64 `json:"lat"`
-	Lng     float64 `json:"lng"`
-	Address string  `json:"address,omitempty"`
-	Type    string  `json:"type,omitempty"` // "pickup", "dropoff", "stop"
-	Order   int     `json:"order,omitempty"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Address     string  `json:"address,omitempty"`
+	Type        string  `json:"type,omitempty"` // "pickup", "dropoff", "stop"
+	Order       int     `json:"order,omitempty"`
+	ContactName string  `json:"contact_name,omitempty"`
+	Phone       string  `json:"phone,omitempty"`
+	Notes       string  `json:"notes,omitempty"` // Instructions for the driver, e.g. "gate 3"
+}
+
+// MaskContact returns a copy of the point with contact details hidden.
+// Only the last 3 digits of the phone number are kept.
+func (p PointVO) MaskContact() PointVO {
+	if p.ContactName != "" {
+		p.ContactName = maskKeepLast(p.ContactName, 1)
+	}
+	if p.Phone != "" {
+		p.Phone = maskKeepLast(p.Phone, 3)
+	}
+	p.Notes = ""
+	return p
+}
+
+// WithoutContact returns a copy of the point without contact details,
+// for payloads that leave the order service such as published events.
+func (p PointVO) WithoutContact() PointVO {
+	p.ContactName = ""
+	p.Phone = ""
+	p.Notes = ""
+	return p
+}
+
+func maskKeepLast(s string, keep int) string {
+	runes := []rune(s)
+	if len(runes) <= keep {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:])
 }
@@ -10,6 +10,7 @@ type RideOrderEntity struct {
 	ID            string                 `json:"id"`
 	CreatedBy     string                 `json:"created_by"`
 	CreatorRole   string                 `json:"creator_role"` // "admin", "driver", "customer"
+	PartnerID     string                 `json:"partner_id"`   // Tenant the order belongs to, empty for first-party
 	Status        OrderStatus            `json:"status"`
 	SubStatus     string                 `json:"sub_status"`
 	PromotionCode string                 `json:"promotion_code"`
@@ -106,6 +107,10 @@ func (o *RideOrderEntity) SetPoints(points []PointVO) {
 	o.Points = points
 }
 
+func (o *RideOrderEntity) SetPartner(partnerID string) {
+	o.PartnerID = partnerID
+}
+
 func (o *RideOrderEntity) SetCustomer(customer CustomerVO) {
 	o.Customer = customer
 }
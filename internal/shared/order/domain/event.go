@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"go1/internal/shared/order/domain/entity"
+)
+
+// AggregateTypeOrder identifies order events in the outbox
+const AggregateTypeOrder = "order"
+
+// Order domain event types
+const (
+	EventOrderCreated       = "OrderCreated"
+	EventOrderStatusChanged = "OrderStatusChanged"
+	EventOrderCancelled     = "OrderCancelled"
+)
+
+// OrderEventVersion is the payload schema version of the order events below.
+// Bump it on breaking payload changes so consumers can branch on it.
+const OrderEventVersion = 1
+
+type OrderCreatedEvent struct {
+	OrderID     string           `json:"order_id"`
+	WorkflowID  string           `json:"workflow_id"`
+	PartnerID   string           `json:"partner_id"`
+	CreatedBy   string           `json:"created_by"`
+	CreatorRole string           `json:"creator_role"`
+	CustomerID  string           `json:"customer_id"`
+	DriverID    string           `json:"driver_id,omitempty"`
+	Status      string           `json:"status"`
+	ServiceID   int32            `json:"service_id"`
+	ServiceType string           `json:"service_type"`
+	Points      []entity.PointVO `json:"points"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type OrderStatusChangedEvent struct {
+	OrderID   string    `json:"order_id"`
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type OrderCancelledEvent struct {
+	OrderID     string    `json:"order_id"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+func NewOrderCreatedEvent(order *entity.RideOrderEntity) OrderCreatedEvent {
+	return OrderCreatedEvent{
+		OrderID:     order.ID,
+		WorkflowID:  order.WorkflowID,
+		PartnerID:   order.PartnerID,
+		CreatedBy:   order.CreatedBy,
+		CreatorRole: order.CreatorRole,
+		CustomerID:  order.Customer.ID,
+		DriverID:    order.Driver.ID,
+		Status:      string(order.Status),
+		ServiceID:   order.Service.ID,
+		ServiceType: order.Service.Type,
+		Points:      pointsWithoutContact(order.Points),
+		CreatedAt:   order.CreatedAt,
+	}
+}
+
+// pointsWithoutContact strips contact PII; consumers read it from the order when they need it
+func pointsWithoutContact(points []entity.PointVO) []entity.PointVO {
+	stripped := make([]entity.PointVO, len(points))
+	for i, p := range points {
+		stripped[i] = p.WithoutContact()
+	}
+	return stripped
+}
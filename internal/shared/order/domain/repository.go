@@ -2,9 +2,13 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"go1/internal/shared/order/domain/entity"
 )
 
+// ErrOrderNotFound is returned by OrderRepository when no order matches
+var ErrOrderNotFound = errors.New("order not found")
+
 // OrderRepository defines the interface for order storage
 type OrderRepository interface {
 	Create(ctx context.Context, order *entity.RideOrderEntity) error
@@ -0,0 +1,64 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go1/internal/shared/order/domain"
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const orderKeyPrefix = "order:"
+
+type redisOrderCache struct {
+	rd  *redis.RedisClient
+	ttl time.Duration
+}
+
+func NewRedisOrderCache(rd *redis.RedisClient, ttl time.Duration) domain.OrderCache {
+	return &redisOrderCache{rd: rd, ttl: ttl}
+}
+
+func (c *redisOrderCache) Get(ctx context.Context, id string) (*entity.RideOrderEntity, error) {
+	data, err := c.rd.Client.Get(ctx, orderKey(id)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redisOrderCache.Get: %w", err)
+	}
+
+	var order entity.RideOrderEntity
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, fmt.Errorf("redisOrderCache.Get: %w", err)
+	}
+	return &order, nil
+}
+
+func (c *redisOrderCache) Set(ctx context.Context, order *entity.RideOrderEntity) error {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("redisOrderCache.Set: %w", err)
+	}
+	if err := c.rd.Client.Set(ctx, orderKey(order.ID), data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("redisOrderCache.Set: %w", err)
+	}
+	return nil
+}
+
+func (c *redisOrderCache) Invalidate(ctx context.Context, id string) error {
+	if err := c.rd.Client.Del(ctx, orderKey(id)).Err(); err != nil {
+		return fmt.Errorf("redisOrderCache.Invalidate: %w", err)
+	}
+	return nil
+}
+
+func orderKey(id string) string {
+	return orderKeyPrefix + id
+}
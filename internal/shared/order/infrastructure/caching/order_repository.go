@@ -0,0 +1,87 @@
+package caching
+
+import (
+	"context"
+
+	"go1/internal/shared/order/domain"
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/logger"
+	"go1/pkg/metrics"
+	"go1/pkg/request"
+)
+
+const orderCacheName = "order"
+
+// cachedOrderRepository decorates an OrderRepository with a cache-first GetByID.
+// Writes go to the wrapped repository and drop the cached entry; entries changed by other
+// writers are invalidated by the CDC consumer on the orders topic.
+type cachedOrderRepository struct {
+	domain.OrderRepository
+	cache domain.OrderCache
+}
+
+func NewCachedOrderRepository(repo domain.OrderRepository, cache domain.OrderCache) domain.OrderRepository {
+	return &cachedOrderRepository{OrderRepository: repo, cache: cache}
+}
+
+func (r *cachedOrderRepository) GetByID(ctx context.Context, id string) (*entity.RideOrderEntity, error) {
+	order, err := r.cache.Get(ctx, id)
+	if err != nil {
+		// Degrade to the database when the cache is unavailable
+		metrics.CacheRequestsTotal.WithLabelValues(orderCacheName, "error").Inc()
+		logger.Log.Warn("Order cache read failed", logger.Field{Key: "orderID", Value: id}, logger.Field{Key: "error", Value: err})
+	}
+	if order != nil {
+		metrics.CacheRequestsTotal.WithLabelValues(orderCacheName, "hit").Inc()
+		// Apply the same tenant scope as the database query
+		if partnerID, scoped := request.PartnerScope(ctx); scoped && partnerID != order.PartnerID {
+			return nil, domain.ErrOrderNotFound
+		}
+		return order, nil
+	}
+	if err == nil {
+		metrics.CacheRequestsTotal.WithLabelValues(orderCacheName, "miss").Inc()
+	}
+
+	order, err = r.OrderRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.cache.Set(ctx, order); err != nil {
+		logger.Log.Warn("Order cache write failed", logger.Field{Key: "orderID", Value: id}, logger.Field{Key: "error", Value: err})
+	}
+	return order, nil
+}
+
+func (r *cachedOrderRepository) Update(ctx context.Context, order *entity.RideOrderEntity) error {
+	if err := r.OrderRepository.Update(ctx, order); err != nil {
+		return err
+	}
+	r.invalidate(ctx, order.ID)
+	return nil
+}
+
+func (r *cachedOrderRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	if err := r.OrderRepository.UpdateStatus(ctx, id, status); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *cachedOrderRepository) Delete(ctx context.Context, id string) error {
+	if err := r.OrderRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *cachedOrderRepository) invalidate(ctx context.Context, id string) {
+	if err := r.cache.Invalidate(ctx, id); err != nil {
+		logger.Log.Warn("Order cache invalidation failed", logger.Field{Key: "orderID", Value: id}, logger.Field{Key: "error", Value: err})
+		return
+	}
+	metrics.CacheInvalidationsTotal.WithLabelValues(orderCacheName, "write").Inc()
+}
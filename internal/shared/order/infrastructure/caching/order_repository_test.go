@@ -0,0 +1,205 @@
+package caching
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go1/internal/shared/order/domain"
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/logger"
+	"go1/pkg/request"
+)
+
+// fakeOrderCache is an in-memory OrderCache; err fails every call
+type fakeOrderCache struct {
+	orders      map[string]*entity.RideOrderEntity
+	err         error
+	invalidated []string
+}
+
+func (c *fakeOrderCache) Get(_ context.Context, id string) (*entity.RideOrderEntity, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.orders[id], nil
+}
+
+func (c *fakeOrderCache) Set(_ context.Context, order *entity.RideOrderEntity) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.orders[order.ID] = order
+	return nil
+}
+
+func (c *fakeOrderCache) Invalidate(_ context.Context, id string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.invalidated = append(c.invalidated, id)
+	delete(c.orders, id)
+	return nil
+}
+
+// fakeOrderRepository serves orders from memory and counts reads; writeErr fails every write
+type fakeOrderRepository struct {
+	domain.OrderRepository
+	orders   map[string]*entity.RideOrderEntity
+	reads    int
+	writeErr error
+}
+
+func (r *fakeOrderRepository) GetByID(_ context.Context, id string) (*entity.RideOrderEntity, error) {
+	r.reads++
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (r *fakeOrderRepository) Update(_ context.Context, order *entity.RideOrderEntity) error {
+	return r.writeErr
+}
+
+func (r *fakeOrderRepository) UpdateStatus(_ context.Context, id string, status string) error {
+	return r.writeErr
+}
+
+func (r *fakeOrderRepository) Delete(_ context.Context, id string) error {
+	return r.writeErr
+}
+
+func TestCachedGetByID(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	cached := &entity.RideOrderEntity{ID: "o1", Status: "cached"}
+	stored := &entity.RideOrderEntity{ID: "o1", Status: "stored"}
+	cacheErr := errors.New("redis down")
+
+	tests := []struct {
+		name      string
+		cached    *entity.RideOrderEntity
+		cacheErr  error
+		stored    *entity.RideOrderEntity
+		want      *entity.RideOrderEntity
+		wantErr   error
+		wantReads int
+		wantSet   bool // The result is cached afterwards
+	}{
+		{name: "hit", cached: cached, stored: stored, want: cached},
+		{name: "miss reads through and fills the cache", stored: stored, want: stored, wantReads: 1, wantSet: true},
+		{name: "cache error falls back to the database", cacheErr: cacheErr, stored: stored, want: stored, wantReads: 1},
+		{name: "missing order is not cached", wantErr: domain.ErrOrderNotFound, wantReads: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &fakeOrderCache{orders: map[string]*entity.RideOrderEntity{}, err: tt.cacheErr}
+			if tt.cached != nil {
+				cache.orders["o1"] = tt.cached
+			}
+			repo := &fakeOrderRepository{orders: map[string]*entity.RideOrderEntity{}}
+			if tt.stored != nil {
+				repo.orders["o1"] = tt.stored
+			}
+
+			got, err := NewCachedOrderRepository(repo, cache).GetByID(context.Background(), "o1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetByID() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetByID() = %+v, want %+v", got, tt.want)
+			}
+			if repo.reads != tt.wantReads {
+				t.Errorf("database read %d times, want %d", repo.reads, tt.wantReads)
+			}
+			if tt.wantSet && cache.orders["o1"] != tt.stored {
+				t.Errorf("cached order = %+v, want the stored order", cache.orders["o1"])
+			}
+			if tt.wantErr != nil && len(cache.orders) != 0 {
+				t.Errorf("cache = %v, want empty", cache.orders)
+			}
+		})
+	}
+}
+
+func TestCachedWritesInvalidate(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	writes := map[string]func(repo domain.OrderRepository) error{
+		"Update": func(repo domain.OrderRepository) error {
+			return repo.Update(context.Background(), &entity.RideOrderEntity{ID: "o1"})
+		},
+		"UpdateStatus": func(repo domain.OrderRepository) error {
+			return repo.UpdateStatus(context.Background(), "o1", "completed")
+		},
+		"Delete": func(repo domain.OrderRepository) error {
+			return repo.Delete(context.Background(), "o1")
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			cache := &fakeOrderCache{orders: map[string]*entity.RideOrderEntity{"o1": {ID: "o1"}}}
+			if err := write(NewCachedOrderRepository(&fakeOrderRepository{}, cache)); err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if len(cache.invalidated) != 1 || cache.invalidated[0] != "o1" {
+				t.Errorf("invalidated = %v, want [o1]", cache.invalidated)
+			}
+		})
+
+		t.Run(name+" failure keeps the cache", func(t *testing.T) {
+			writeErr := errors.New("database down")
+			cache := &fakeOrderCache{orders: map[string]*entity.RideOrderEntity{"o1": {ID: "o1"}}}
+			if err := write(NewCachedOrderRepository(&fakeOrderRepository{writeErr: writeErr}, cache)); !errors.Is(err, writeErr) {
+				t.Fatalf("%s() error = %v, want %v", name, err, writeErr)
+			}
+			if len(cache.invalidated) != 0 {
+				t.Errorf("invalidated = %v, want none", cache.invalidated)
+			}
+		})
+	}
+
+	t.Run("invalidation failure does not fail the write", func(t *testing.T) {
+		cache := &fakeOrderCache{err: errors.New("redis down")}
+		if err := writes["UpdateStatus"](NewCachedOrderRepository(&fakeOrderRepository{}, cache)); err != nil {
+			t.Fatalf("UpdateStatus() error = %v", err)
+		}
+	})
+}
+
+func TestCachedGetByIDAppliesPartnerScope(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	order := &entity.RideOrderEntity{ID: "o1", PartnerID: "acme"}
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{name: "same partner", ctx: request.WithUser(context.Background(), &request.UserContext{UserID: "c1", PartnerID: "acme"})},
+		{name: "other partner", ctx: request.WithUser(context.Background(), &request.UserContext{UserID: "c1", PartnerID: "other"}), wantErr: domain.ErrOrderNotFound},
+		{name: "first-party caller", ctx: request.WithUser(context.Background(), &request.UserContext{UserID: "c1"}), wantErr: domain.ErrOrderNotFound},
+		{name: "internal caller", ctx: context.Background()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &fakeOrderCache{orders: map[string]*entity.RideOrderEntity{"o1": order}}
+			repo := &fakeOrderRepository{}
+			got, err := NewCachedOrderRepository(repo, cache).GetByID(tt.ctx, "o1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetByID() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != order {
+				t.Errorf("GetByID() = %+v, want the cached order", got)
+			}
+			if repo.reads != 0 {
+				t.Errorf("database read %d times on a cache hit", repo.reads)
+			}
+		})
+	}
+}
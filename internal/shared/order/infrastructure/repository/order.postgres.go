@@ -10,6 +10,8 @@ import (
 	"go1/internal/shared/order/domain/entity"
 	"go1/internal/shared/order/infrastructure/repository/postgres/mapper"
 	"go1/internal/shared/order/infrastructure/repository/postgres/model"
+	"go1/pkg/outbox"
+	"go1/pkg/request"
 	"go1/pkg/utils"
 
 	"github.com/jackc/pgx/v5"
@@ -31,13 +33,15 @@ type postgresOrderRepository struct {
 	db PgxPoolIface
 }
 
-var errNotFound = fmt.Errorf("not found")
-
 func NewPostgresOrderRepository(db *pgxpool.Pool) domain.OrderRepository {
 	return &postgresOrderRepository{db: db}
 }
 
 func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.RideOrderEntity) error {
+	if partnerID, scoped := request.PartnerScope(ctx); scoped && partnerID != order.PartnerID {
+		return fmt.Errorf("postgresOrderRepository.Create: order partner %q does not match caller partner %q", order.PartnerID, partnerID)
+	}
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -46,11 +50,13 @@ func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.Ride
 
 	query := `INSERT INTO orders (
 		id, created_by, status, payment_method, metadata, workflow_id, service_id, service_type, service_name, created_at, updated_at,
-		sub_status, promotion_code, fee_id, has_insurance, order_time, completed_time, cancel_time, platform, is_schedule, now_order, now_order_code
+		sub_status, promotion_code, fee_id, has_insurance, order_time, completed_time, cancel_time, platform, is_schedule, now_order, now_order_code,
+		creator_role, customer_id, driver_id, partner_id
 	) 
 	VALUES (
 		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
-		$12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
+		$12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+		$23, $24, $25, $26
 	) 
 	RETURNING id, created_at, updated_at`
 
@@ -83,6 +89,10 @@ func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.Ride
 		order.IsSchedule,
 		order.NowOrder,
 		utils.EmptyToNil(order.NowOrderCode),
+		order.CreatorRole,
+		order.Customer.ID,
+		order.Driver.ID,
+		order.PartnerID,
 	).Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
 
 	if err != nil {
@@ -90,14 +100,19 @@ func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.Ride
 	}
 
 	// Insert Order Points
-	pointQuery := `INSERT INTO order_points (order_id, lat, lng, address, type, ordering) VALUES ($1, $2, $3, $4, $5, $6)`
+	pointQuery := `INSERT INTO order_points (order_id, lat, lng, address, type, ordering, contact_name, phone, notes) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 	for i, p := range order.Points {
-		_, err := tx.Exec(ctx, pointQuery, order.ID, p.Lat, p.Lng, p.Address, p.Type, i)
+		_, err := tx.Exec(ctx, pointQuery, order.ID, p.Lat, p.Lng, p.Address, p.Type, i, p.ContactName, p.Phone, p.Notes)
 		if err != nil {
 			return fmt.Errorf("failed to insert order point: %w", err)
 		}
 	}
 
+	event := outbox.NewMessage(domain.AggregateTypeOrder, order.ID, domain.EventOrderCreated, domain.OrderEventVersion, domain.NewOrderCreatedEvent(order))
+	if err := outbox.Insert(ctx, tx, event); err != nil {
+		return fmt.Errorf("postgresOrderRepository.Create: %w", err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -112,13 +127,15 @@ func (r *postgresOrderRepository) Create(ctx context.Context, order *entity.Ride
 func (r *postgresOrderRepository) GetByID(ctx context.Context, id string) (*entity.RideOrderEntity, error) {
 	query := `SELECT 
 		id, created_by, status, payment_method, metadata, workflow_id, service_id, service_type, service_name, created_at, updated_at,
-		sub_status, promotion_code, fee_id, has_insurance, order_time, completed_time, cancel_time, platform, is_schedule, now_order, now_order_code
+		sub_status, promotion_code, fee_id, has_insurance, order_time, completed_time, cancel_time, platform, is_schedule, now_order, now_order_code,
+		creator_role, customer_id, driver_id, partner_id
 	FROM orders WHERE id = $1`
+	query, args := scopeToPartner(ctx, query, id)
 
 	var m model.OrderModel
 	var subStatus, promotionCode, feeID, nowOrderCode *string
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&m.ID,
 		&m.CreatedBy,
 		&m.Status,
@@ -141,10 +158,14 @@ func (r *postgresOrderRepository) GetByID(ctx context.Context, id string) (*enti
 		&m.IsSchedule,
 		&m.NowOrder,
 		&nowOrderCode,
+		&m.CreatorRole,
+		&m.CustomerID,
+		&m.DriverID,
+		&m.PartnerID,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, errNotFound
+			return nil, domain.ErrOrderNotFound
 		}
 		return nil, fmt.Errorf("postgresOrderRepository.GetByID: %w", err)
 	}
@@ -162,21 +183,81 @@ func (r *postgresOrderRepository) GetByID(ctx context.Context, id string) (*enti
 		m.NowOrderCode = *nowOrderCode
 	}
 
-	return mapper.ToOrderDomain(&m), nil
+	order := mapper.ToOrderDomain(&m)
+
+	points, err := r.getPoints(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	order.Points = points
+
+	return order, nil
+}
+
+func (r *postgresOrderRepository) getPoints(ctx context.Context, orderID string) ([]entity.PointVO, error) {
+	query := `SELECT order_id, lat, lng, address, type, ordering, contact_name, phone, notes
+	FROM order_points WHERE order_id = $1 ORDER BY ordering`
+
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("postgresOrderRepository.getPoints: %w", err)
+	}
+	defer rows.Close()
+
+	var points []entity.PointVO
+	for rows.Next() {
+		var m model.OrderPointModel
+		if err := rows.Scan(
+			&m.OrderID,
+			&m.Lat,
+			&m.Lng,
+			&m.Address,
+			&m.Type,
+			&m.Ordering,
+			&m.ContactName,
+			&m.Phone,
+			&m.Notes,
+		); err != nil {
+			return nil, fmt.Errorf("postgresOrderRepository.getPoints: %w", err)
+		}
+		points = append(points, mapper.ToPointDomain(&m))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgresOrderRepository.getPoints: %w", err)
+	}
+
+	return points, nil
 }
 
 func (r *postgresOrderRepository) UpdateStatus(ctx context.Context, id string, status string) error {
-	query := `UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.Exec(ctx, query, status, time.Now(), id)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query, args := scopeToPartner(ctx, `UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`, status, now, id)
+	tag, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("postgresOrderRepository.UpdateStatus: %w", err)
 	}
+
+	if tag.RowsAffected() > 0 {
+		if err := outbox.Insert(ctx, tx, statusChangeEvents(id, status, now)...); err != nil {
+			return fmt.Errorf("postgresOrderRepository.UpdateStatus: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
 func (r *postgresOrderRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM orders WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+	query, args := scopeToPartner(ctx, `DELETE FROM orders WHERE id = $1`, id)
+	_, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("postgresOrderRepository.Delete: %w", err)
 	}
@@ -184,14 +265,67 @@ func (r *postgresOrderRepository) Delete(ctx context.Context, id string) error {
 }
 
 func (r *postgresOrderRepository) Update(ctx context.Context, order *entity.RideOrderEntity) error {
-	query := `UPDATE orders SET status = $1, payment_method = $2, metadata = $3, updated_at = $4 WHERE id = $5`
 	metadataBytes, err := json.Marshal(order.Metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-	_, err = r.db.Exec(ctx, query, order.Status, order.Payment.Method, metadataBytes, time.Now(), order.ID)
+
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	// Lock the row to compare the previous status
+	query, args := scopeToPartner(ctx, `SELECT status FROM orders WHERE id = $1`, order.ID)
+	var previousStatus string
+	if err := tx.QueryRow(ctx, query+" FOR UPDATE", args...).Scan(&previousStatus); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrOrderNotFound
+		}
+		return fmt.Errorf("postgresOrderRepository.Update: %w", err)
+	}
+
+	query, args = scopeToPartner(ctx, `UPDATE orders SET status = $1, payment_method = $2, metadata = $3, updated_at = $4 WHERE id = $5`,
+		order.Status, order.Payment.Method, metadataBytes, now, order.ID)
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
 		return fmt.Errorf("postgresOrderRepository.Update: %w", err)
 	}
+
+	if previousStatus != string(order.Status) {
+		if err := outbox.Insert(ctx, tx, statusChangeEvents(order.ID, string(order.Status), now)...); err != nil {
+			return fmt.Errorf("postgresOrderRepository.Update: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
+
+// statusChangeEvents builds the outbox events for a status transition
+func statusChangeEvents(orderID string, status string, changedAt time.Time) []outbox.Message {
+	events := []outbox.Message{
+		outbox.NewMessage(domain.AggregateTypeOrder, orderID, domain.EventOrderStatusChanged, domain.OrderEventVersion,
+			domain.OrderStatusChangedEvent{OrderID: orderID, Status: status, ChangedAt: changedAt}),
+	}
+	if status == string(entity.StatusCancelled) {
+		events = append(events, outbox.NewMessage(domain.AggregateTypeOrder, orderID, domain.EventOrderCancelled, domain.OrderEventVersion,
+			domain.OrderCancelledEvent{OrderID: orderID, CancelledAt: changedAt}))
+	}
+	return events
+}
+
+// scopeToPartner appends a tenant filter to a query ending in a WHERE clause when it is issued
+// on behalf of an API caller, so one partner can never read or modify another partner's orders.
+// Internal callers (workflows, consumers) carry no user context and are not scoped.
+func scopeToPartner(ctx context.Context, query string, args ...interface{}) (string, []interface{}) {
+	partnerID, scoped := request.PartnerScope(ctx)
+	if !scoped {
+		return query, args
+	}
+	args = append(args, partnerID)
+	return fmt.Sprintf("%s AND partner_id = $%d", query, len(args)), args
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go1/pkg/request"
+)
+
+func TestScopeToPartner(t *testing.T) {
+	const query = `UPDATE orders SET status = $1 WHERE id = $2`
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "partner caller",
+			ctx:       request.WithUser(context.Background(), &request.UserContext{UserID: "c1", PartnerID: "acme"}),
+			wantQuery: query + ` AND partner_id = $3`,
+			wantArgs:  []interface{}{"done", "o1", "acme"},
+		},
+		{
+			name:      "first-party caller is scoped to first-party orders",
+			ctx:       request.WithUser(context.Background(), &request.UserContext{UserID: "c1"}),
+			wantQuery: query + ` AND partner_id = $3`,
+			wantArgs:  []interface{}{"done", "o1", ""},
+		},
+		{
+			name:      "internal caller",
+			ctx:       context.Background(),
+			wantQuery: query,
+			wantArgs:  []interface{}{"done", "o1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery, gotArgs := scopeToPartner(tt.ctx, query, "done", "o1")
+			if gotQuery != tt.wantQuery {
+				t.Errorf("query = %q, want %q", gotQuery, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
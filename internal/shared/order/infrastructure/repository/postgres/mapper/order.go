@@ -14,6 +14,8 @@ func ToOrderDomain(m *model.OrderModel) *entity.RideOrderEntity {
 	return &entity.RideOrderEntity{
 		ID:            m.ID,
 		CreatedBy:     m.CreatedBy,
+		CreatorRole:   m.CreatorRole,
+		PartnerID:     m.PartnerID,
 		Status:        entity.OrderStatus(m.Status),
 		SubStatus:     m.SubStatus,
 		PromotionCode: m.PromotionCode,
@@ -34,7 +36,22 @@ func ToOrderDomain(m *model.OrderModel) *entity.RideOrderEntity {
 			Type: m.ServiceType,
 			Name: m.ServiceName,
 		},
+		Customer:  entity.CustomerVO{ID: m.CustomerID},
+		Driver:    entity.DriverVO{ID: m.DriverID},
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 	}
 }
+
+func ToPointDomain(m *model.OrderPointModel) entity.PointVO {
+	return entity.PointVO{
+		Lat:         m.Lat,
+		Lng:         m.Lng,
+		Address:     m.Address,
+		Type:        m.Type,
+		Order:       m.Ordering,
+		ContactName: m.ContactName,
+		Phone:       m.Phone,
+		Notes:       m.Notes,
+	}
+}
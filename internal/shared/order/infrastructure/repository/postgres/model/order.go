@@ -5,6 +5,10 @@ import "time"
 type OrderModel struct {
 	ID            string     `db:"id"`
 	CreatedBy     string     `db:"created_by"`
+	CreatorRole   string     `db:"creator_role"`
+	PartnerID     string     `db:"partner_id"`
+	CustomerID    string     `db:"customer_id"`
+	DriverID      string     `db:"driver_id"`
 	Status        string     `db:"status"`
 	SubStatus     string     `db:"sub_status"`
 	PromotionCode string     `db:"promotion_code"`
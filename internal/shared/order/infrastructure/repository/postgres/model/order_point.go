@@ -0,0 +1,13 @@
+package model
+
+type OrderPointModel struct {
+	OrderID     string  `db:"order_id"`
+	Lat         float64 `db:"lat"`
+	Lng         float64 `db:"lng"`
+	Address     string  `db:"address"`
+	Type        string  `db:"type"`
+	Ordering    int     `db:"ordering"`
+	ContactName string  `db:"contact_name"`
+	Phone       string  `db:"phone"`
+	Notes       string  `db:"notes"`
+}
@@ -1,19 +1,28 @@
 package order
 
 import (
+	"time"
+
+	"go1/config"
 	orderHandler "go1/internal/api/handlers/order"
 	"go1/internal/shared/order/application"
 	"go1/internal/shared/order/application/validator"
+	"go1/internal/shared/order/infrastructure/caching"
 	"go1/internal/shared/order/infrastructure/gateway"
 	"go1/internal/shared/order/infrastructure/repository"
+	"go1/pkg/redis"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Init(router *gin.Engine, db *pgxpool.Pool) {
+func Init(router *gin.Engine, db *pgxpool.Pool, rd *redis.RedisClient, cfg *config.Config) {
 	// Infrastructure
 	repo := repository.NewPostgresOrderRepository(db)
+	if cfg.Cache.Enabled {
+		cache := caching.NewRedisOrderCache(rd, time.Duration(cfg.Cache.OrderTTLSeconds)*time.Second)
+		repo = caching.NewCachedOrderRepository(repo, cache)
+	}
 
 	pricingGw := gateway.NewPricingGateway()
 	serviceGw := gateway.NewServiceGateway()
@@ -21,7 +30,7 @@ func Init(router *gin.Engine, db *pgxpool.Pool) {
 	locationGw := gateway.NewLocationGateway()
 
 	// Application
-	rideValidator := validator.NewRideOrderValidator()
+	rideValidator := validator.NewRideOrderValidator(cfg.Partners)
 
 	mapper := application.NewOrderMapper()
 	service := application.NewOrderService(
@@ -32,6 +41,7 @@ func Init(router *gin.Engine, db *pgxpool.Pool) {
 		paymentGw,
 		locationGw,
 		rideValidator,
+		application.NewOrderPolicy(),
 	)
 
 	// Presentation
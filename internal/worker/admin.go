@@ -0,0 +1,250 @@
+package worker
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go1/pkg/auth"
+	"go1/pkg/kafka"
+	"go1/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminPrincipalKey holds the name of the operator whose token authenticated the request
+const adminPrincipalKey = "adminPrincipal"
+
+// adminHandler serves the worker's operational endpoints
+type adminHandler struct {
+	dlq     *kafka.DLQ
+	manager *KafkaManager
+}
+
+func newAdminRouter(tokens map[string]string, dlq *kafka.DLQ, manager *KafkaManager) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(adminAuthMiddleware(tokens))
+
+	h := &adminHandler{dlq: dlq, manager: manager}
+	admin := router.Group("/admin")
+	{
+		admin.GET("/dlq/:topic/messages", h.listDLQ)
+		admin.POST("/dlq/:topic/replay", h.replayDLQ)
+
+		admin.GET("/consumer", h.consumerStatus)
+		admin.POST("/consumer/pause", h.pauseConsumer)
+		admin.POST("/consumer/resume", h.resumeConsumer)
+		admin.POST("/consumer/stop", h.stopConsumer)
+		admin.POST("/consumer/start", h.startConsumer)
+		admin.POST("/consumer/reset-offsets", h.resetOffsets)
+	}
+	return router
+}
+
+// adminAuthMiddleware requires the bearer token of one of the configured operators
+// and records that operator as the request's principal.
+func adminAuthMiddleware(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := []byte(auth.BearerToken(c.GetHeader("Authorization")))
+		principal := ""
+		for name, token := range tokens {
+			// Compare against every token so the response time does not reveal which one matched
+			if token != "" && subtle.ConstantTimeCompare(given, []byte(token)) == 1 {
+				principal = name
+			}
+		}
+		if principal == "" {
+			response.Error(c, http.StatusUnauthorized, "invalid admin token")
+			c.Abort()
+			return
+		}
+		c.Set(adminPrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// validateAdminTokens refuses an admin server that anyone could call
+func validateAdminTokens(tokens map[string]string) error {
+	if len(tokens) == 0 {
+		return errors.New("workerAdmin is enabled but workerAdmin.tokens is empty")
+	}
+	for name, token := range tokens {
+		if token == "" {
+			return fmt.Errorf("workerAdmin.tokens.%s is empty", name)
+		}
+	}
+	return nil
+}
+
+// listDLQ lists DLQ messages.
+// Query: key, error, since, until (RFC3339), limit, offsets (partition:offset,...)
+func (h *adminHandler) listDLQ(c *gin.Context) {
+	filter, err := dlqFilterFromQuery(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 100
+	}
+
+	messages, err := h.dlq.List(c.Request.Context(), c.Param("topic"), filter)
+	if errors.Is(err, kafka.ErrNotDLQTopic) {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Success(c, messages)
+}
+
+// replayDLQ replays the DLQ messages selected by the JSON filter body.
+// The authenticated operator is recorded on every replay.
+func (h *adminHandler) replayDLQ(c *gin.Context) {
+	replayedBy := c.GetString(adminPrincipalKey)
+
+	var filter kafka.DLQFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		response.HandleBindingError(c, err)
+		return
+	}
+
+	replays, err := h.dlq.Replay(c.Request.Context(), c.Param("topic"), filter, replayedBy)
+	if errors.Is(err, kafka.ErrNotDLQTopic) || errors.Is(err, kafka.ErrDLQFilterRequired) {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		// Report what was replayed before the failure
+		c.JSON(http.StatusInternalServerError, response.Response{Success: false, Data: replays, Error: err.Error()})
+		return
+	}
+	response.Success(c, replays)
+}
+
+func dlqFilterFromQuery(c *gin.Context) (kafka.DLQFilter, error) {
+	filter := kafka.DLQFilter{
+		Key:           c.Query("key"),
+		ErrorContains: c.Query("error"),
+	}
+	var err error
+	if v := c.Query("since"); v != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.Query("offsets"); v != "" {
+		if filter.Refs, err = kafka.ParseDLQRefs(v); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+// topicPartitionsRequest selects partitions of a topic; no partitions means the whole topic
+type topicPartitionsRequest struct {
+	Topic      string  `json:"topic" binding:"required"`
+	Partitions []int32 `json:"partitions"`
+}
+
+// resetOffsetsRequest resets to an explicit offset or to the first message at or after a time
+type resetOffsetsRequest struct {
+	topicPartitionsRequest
+	Offset    *int64    `json:"offset"`
+	Timestamp time.Time `json:"timestamp"` // RFC3339
+}
+
+// consumerStatus shows this worker's assignment and pauses, and the group's offsets and lag
+func (h *adminHandler) consumerStatus(c *gin.Context) {
+	status, err := h.manager.Status()
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// pauseConsumer pauses topic partitions on this worker; other workers keep their assignment
+func (h *adminHandler) pauseConsumer(c *gin.Context) {
+	var req topicPartitionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleBindingError(c, err)
+		return
+	}
+	if err := h.manager.Pause(req.Topic, req.Partitions); err != nil {
+		consumerError(c, err)
+		return
+	}
+	response.Success(c, h.manager.consumer.State())
+}
+
+func (h *adminHandler) resumeConsumer(c *gin.Context) {
+	var req topicPartitionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleBindingError(c, err)
+		return
+	}
+	if err := h.manager.Resume(req.Topic, req.Partitions); err != nil {
+		consumerError(c, err)
+		return
+	}
+	response.Success(c, h.manager.consumer.State())
+}
+
+// stopConsumer makes this worker leave the consumer group, e.g. before resetting offsets
+func (h *adminHandler) stopConsumer(c *gin.Context) {
+	h.manager.Stop()
+	response.Success(c, h.manager.consumer.State())
+}
+
+func (h *adminHandler) startConsumer(c *gin.Context) {
+	h.manager.Start()
+	response.Success(c, h.manager.consumer.State())
+}
+
+// resetOffsets moves the group's committed offsets. Every worker of the group must be stopped.
+func (h *adminHandler) resetOffsets(c *gin.Context) {
+	var req resetOffsetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.HandleBindingError(c, err)
+		return
+	}
+	if req.Offset == nil && req.Timestamp.IsZero() {
+		response.Error(c, http.StatusBadRequest, "offset or timestamp is required")
+		return
+	}
+
+	offsets, err := h.manager.ResetOffsets(req.Topic, req.Partitions, kafka.OffsetReset{Offset: req.Offset, Timestamp: req.Timestamp})
+	if err != nil {
+		consumerError(c, err)
+		return
+	}
+	response.Success(c, offsets)
+}
+
+func consumerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownTopic):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, kafka.ErrGroupActive):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.HandleError(c, err)
+	}
+}
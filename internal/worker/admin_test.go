@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := map[string]string{"alice": "token-a", "bob": "token-b", "disabled": ""}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{name: "first operator", authorization: "Bearer token-a", wantStatus: http.StatusOK, wantPrincipal: "alice"},
+		{name: "second operator", authorization: "Bearer token-b", wantStatus: http.StatusOK, wantPrincipal: "bob"},
+		{name: "wrong token", authorization: "Bearer token-c", wantStatus: http.StatusUnauthorized},
+		{name: "empty bearer does not match an empty token", authorization: "Bearer ", wantStatus: http.StatusUnauthorized},
+		{name: "missing header", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(adminAuthMiddleware(tokens))
+			router.GET("/admin", func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(adminPrincipalKey))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantPrincipal != "" && rec.Body.String() != tt.wantPrincipal {
+				t.Errorf("principal = %q, want %q", rec.Body.String(), tt.wantPrincipal)
+			}
+		})
+	}
+}
+
+func TestValidateAdminTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  map[string]string
+		wantErr bool
+	}{
+		{name: "no tokens", wantErr: true},
+		{name: "empty token", tokens: map[string]string{"ops": ""}, wantErr: true},
+		{name: "configured", tokens: map[string]string{"ops": "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAdminTokens(tt.tokens); (err != nil) != tt.wantErr {
+				t.Errorf("validateAdminTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
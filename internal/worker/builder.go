@@ -24,6 +24,7 @@ type TopicRetryConfig struct {
 type WorkerBuilder struct {
 	config       *config.Config
 	topicConfigs []TopicConfig
+	dedup        kafka.DedupStore
 }
 
 func NewWorkerBuilder(cfg *config.Config) *WorkerBuilder {
@@ -33,9 +34,23 @@ func NewWorkerBuilder(cfg *config.Config) *WorkerBuilder {
 	}
 }
 
+// WithDedup sets the processed-message store used by handlers that opt into deduplication
+func (b *WorkerBuilder) WithDedup(store kafka.DedupStore) *WorkerBuilder {
+	b.dedup = store
+	return b
+}
+
 // AddTopic adds a topic to consume with a pre-configured handler
 func (b *WorkerBuilder) AddTopic(name string, handler kafka.MessageHandler) *WorkerBuilder {
-	var retryConfig *TopicRetryConfig
+	b.topicConfigs = append(b.topicConfigs, TopicConfig{
+		Name:    name,
+		Handler: handler,
+		Retry:   b.retryConfig(name),
+	})
+	return b
+}
+
+func (b *WorkerBuilder) retryConfig(name string) *TopicRetryConfig {
 	topicCfg, exists := b.config.Kafka.Retry.Topics[name]
 	if !exists {
 		// Fallback for topics with dots (Viper issue): try replacing dots with underscores
@@ -43,19 +58,13 @@ func (b *WorkerBuilder) AddTopic(name string, handler kafka.MessageHandler) *Wor
 		topicCfg, exists = b.config.Kafka.Retry.Topics[sanitized]
 	}
 
-	if exists && topicCfg.EnableRetry {
-		retryConfig = &TopicRetryConfig{
-			MaxAttempts: topicCfg.MaxAttempts,
-			Backoff:     topicCfg.BackoffMs,
-		}
+	if !exists || !topicCfg.EnableRetry {
+		return nil
+	}
+	return &TopicRetryConfig{
+		MaxAttempts: topicCfg.MaxAttempts,
+		Backoff:     topicCfg.BackoffMs,
 	}
-
-	b.topicConfigs = append(b.topicConfigs, TopicConfig{
-		Name:    name,
-		Handler: handler,
-		Retry:   retryConfig,
-	})
-	return b
 }
 
 // Build creates the worker with all configured topics
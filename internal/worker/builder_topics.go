@@ -1,26 +1,49 @@
 package worker
 
 import (
+	"slices"
+	"time"
+
+	"go1/internal/shared/order/infrastructure/caching"
 	"go1/internal/shared/order/infrastructure/repository"
 	consumers "go1/internal/worker/consumers"
+	"go1/pkg/kafka"
 	"go1/pkg/postgres"
+	"go1/pkg/redis"
 
 	"go.temporal.io/sdk/client"
 )
 
 func (b *WorkerBuilder) WithShipmentEvents(pg *postgres.Postgres, temporalClient client.Client) *WorkerBuilder {
 	repo := repository.NewPostgresOrderRepository(pg.Pool)
-	handler := consumers.NewShipmentConsumer(temporalClient, repo)
-	return b.AddTopic(b.config.Kafka.Topics.ShipmentEvents, handler.Handle())
+	handler := consumers.NewShipmentConsumer(temporalClient, repo, b.eventOptions(b.config.Kafka.Topics.ShipmentEvents))
+	// Redelivered events must not signal the workflow twice
+	return b.AddTopic(b.config.Kafka.Topics.ShipmentEvents, kafka.Deduplicate(b.dedup, handler.Handle()))
 }
 
 func (b *WorkerBuilder) WithDispatchEvents(pg *postgres.Postgres, temporalClient client.Client) *WorkerBuilder {
 	repo := repository.NewPostgresOrderRepository(pg.Pool)
-	handler := consumers.NewDispatchConsumer(temporalClient, repo)
-	return b.AddTopic(b.config.Kafka.Topics.DispatchEvents, handler.Handle())
+	handler := consumers.NewDispatchConsumer(temporalClient, repo, b.eventOptions(b.config.Kafka.Topics.DispatchEvents))
+	// Redelivered events must not signal the workflow twice
+	return b.AddTopic(b.config.Kafka.Topics.DispatchEvents, kafka.Deduplicate(b.dedup, handler.Handle()))
 }
 
 func (b *WorkerBuilder) WithOrderEvents(temporalClient client.Client) *WorkerBuilder {
 	handler := consumers.NewOrderConsumer(temporalClient)
+	return b.AddTopic(b.config.Kafka.Topics.OrderDomainEvents, handler.Handle())
+}
+
+// WithOrderCacheInvalidation subscribes to the orders CDC stream to keep the order cache fresh
+func (b *WorkerBuilder) WithOrderCacheInvalidation(rd *redis.RedisClient) *WorkerBuilder {
+	if !b.config.Cache.Enabled {
+		return b
+	}
+	cache := caching.NewRedisOrderCache(rd, time.Duration(b.config.Cache.OrderTTLSeconds)*time.Second)
+	handler := consumers.NewOrderCacheConsumer(cache)
 	return b.AddTopic(b.config.Kafka.Topics.OrderEvents, handler.Handle())
 }
+
+// eventOptions requires the event envelope on topics listed in kafka.strictEventTopics
+func (b *WorkerBuilder) eventOptions(topic string) kafka.EventOptions {
+	return kafka.EventOptions{RequireEnvelope: slices.Contains(b.config.Kafka.StrictEventTopics, topic)}
+}
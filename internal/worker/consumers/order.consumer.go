@@ -2,8 +2,9 @@ package consumers
 
 import (
 	"context"
+	"fmt"
 
-	"go1/internal/shared/order/domain/entity"
+	"go1/internal/shared/order/domain"
 	"go1/internal/shared/order/workflow"
 	"go1/pkg/kafka"
 	"go1/pkg/logger"
@@ -21,46 +22,36 @@ func NewOrderConsumer(temporalClient client.Client) *OrderConsumer {
 	}
 }
 
-type OrderEvent struct {
-	kafka.CDCEvent
-	entity.RideOrderEntity
-	Metadata string `json:"metadata"` // Shadow metadata map from entity to handle JSON string
+// Handle starts the order workflow from OrderCreated domain events relayed from the outbox
+func (h *OrderConsumer) Handle() kafka.MessageHandler {
+	return kafka.HandleEventTypes(map[string]kafka.MessageHandler{
+		domain.EventOrderCreated: kafka.HandleEvent(h.handleOrderCreated),
+	})
 }
 
-func (h *OrderConsumer) Handle() kafka.MessageHandler {
-	return kafka.HandleJSON(func(ctx context.Context, event OrderEvent, meta *kafka.MessageMetadata) error {
-		// Simulate transient error for retry testing
-		// attempt := 0
-		// for _, header := range meta.Headers {
-		// 	if string(header.Key) == "x-attempt" {
-		// 		fmt.Sscanf(string(header.Value), "%d", &attempt)
-		// 		break
-		// 	}
-		// }
-		// if attempt < 1 {
-		// 	err := fmt.Errorf("simulated error for retry testing (attempt %d)", attempt)
-		// 	logger.Log.Error("Simulating transient error", logger.Field{Key: "error", Value: err})
-		// 	return err
-		// }
+func (h *OrderConsumer) handleOrderCreated(ctx context.Context, event kafka.Event[domain.OrderCreatedEvent], meta *kafka.MessageMetadata) error {
+	if event.Version != domain.OrderEventVersion {
+		return kafka.Permanent(fmt.Errorf("unsupported %s version %d", event.Type, event.Version))
+	}
+	order := event.Data
 
-		if event.IsCreated() {
-			workflowID := event.WorkflowID
-			workflowOptions := client.StartWorkflowOptions{
-				ID:        workflowID,
-				TaskQueue: "ORDER_TASK_QUEUE",
-			}
+	workflowID := order.WorkflowID
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: "ORDER_TASK_QUEUE",
+	}
 
-			run, err := h.temporalClient.ExecuteWorkflow(ctx, workflowOptions, workflow.CreateOrderWorkflow, event.ID)
-			if err != nil {
-				logger.Log.Error("Failed to start workflow from CDC", logger.Field{Key: "error", Value: err})
-				return err
-			}
+	run, err := h.temporalClient.ExecuteWorkflow(ctx, workflowOptions, workflow.CreateOrderWorkflow, order.OrderID)
+	if err != nil {
+		logger.Log.Error("Failed to start workflow from OrderCreated", logger.Field{Key: "error", Value: err})
+		return err
+	}
 
-			logger.Log.Info("Workflow started from CDC",
-				logger.Field{Key: "workflowID", Value: workflowID},
-				logger.Field{Key: "runID", Value: run.GetID()})
-		}
+	logger.Log.Info("Workflow started from OrderCreated",
+		logger.Field{Key: "workflowID", Value: workflowID},
+		logger.Field{Key: "partnerID", Value: order.PartnerID},
+		logger.Field{Key: "eventID", Value: event.ID},
+		logger.Field{Key: "runID", Value: run.GetID()})
 
-		return nil
-	})
+	return nil
 }
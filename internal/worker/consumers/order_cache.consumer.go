@@ -0,0 +1,50 @@
+package consumers
+
+import (
+	"context"
+
+	"go1/internal/shared/order/domain"
+	"go1/pkg/kafka"
+	"go1/pkg/logger"
+	"go1/pkg/metrics"
+)
+
+// OrderCacheConsumer keeps the order cache in sync with the orders table using Debezium CDC events
+type OrderCacheConsumer struct {
+	cache domain.OrderCache
+}
+
+func NewOrderCacheConsumer(cache domain.OrderCache) *OrderCacheConsumer {
+	return &OrderCacheConsumer{
+		cache: cache,
+	}
+}
+
+type OrderCacheEvent struct {
+	kafka.CDCEvent
+	ID string `json:"id"`
+}
+
+func (h *OrderCacheConsumer) Handle() kafka.MessageHandler {
+	return kafka.HandleJSON(func(ctx context.Context, event OrderCacheEvent, meta *kafka.MessageMetadata) error {
+		// Creates and snapshots are never cached ahead of a read
+		if !event.IsUpdated() && !event.IsDeleted() {
+			return nil
+		}
+		if event.ID == "" {
+			logger.Log.Warn("CDC event without order id", logger.Field{Key: "offset", Value: meta.Offset})
+			return nil
+		}
+
+		if err := h.cache.Invalidate(ctx, event.ID); err != nil {
+			logger.Log.Error("Failed to invalidate order cache", logger.Field{Key: "orderID", Value: event.ID}, logger.Field{Key: "error", Value: err})
+			return err
+		}
+		metrics.CacheInvalidationsTotal.WithLabelValues("order", "cdc").Inc()
+
+		logger.Log.Debug("Order cache invalidated from CDC",
+			logger.Field{Key: "orderID", Value: event.ID},
+			logger.Field{Key: "op", Value: event.Op})
+		return nil
+	})
+}
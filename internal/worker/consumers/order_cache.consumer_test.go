@@ -0,0 +1,71 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go1/internal/shared/order/domain/entity"
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// recordingOrderCache records invalidations; err fails them
+type recordingOrderCache struct {
+	invalidated []string
+	err         error
+}
+
+func (c *recordingOrderCache) Get(context.Context, string) (*entity.RideOrderEntity, error) {
+	return nil, nil
+}
+
+func (c *recordingOrderCache) Set(context.Context, *entity.RideOrderEntity) error {
+	return nil
+}
+
+func (c *recordingOrderCache) Invalidate(_ context.Context, id string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.invalidated = append(c.invalidated, id)
+	return nil
+}
+
+func TestOrderCacheConsumer(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+	cacheErr := errors.New("redis down")
+
+	tests := []struct {
+		name            string
+		value           []byte
+		cacheErr        error
+		wantErr         error
+		wantInvalidated []string
+	}{
+		{name: "update invalidates", value: []byte(`{"id":"o1","status":"completed","__op":"u"}`), wantInvalidated: []string{"o1"}},
+		{name: "delete invalidates", value: []byte(`{"id":"o1","__op":"d","__deleted":"true"}`), wantInvalidated: []string{"o1"}},
+		{name: "create is ignored", value: []byte(`{"id":"o1","__op":"c"}`)},
+		{name: "snapshot is ignored", value: []byte(`{"id":"o1","__op":"r"}`)},
+		{name: "event without id is ignored", value: []byte(`{"__op":"u"}`)},
+		{name: "tombstone is skipped", value: nil},
+		{name: "cache failure is retried", value: []byte(`{"id":"o1","__op":"u"}`), cacheErr: cacheErr, wantErr: cacheErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &recordingOrderCache{err: tt.cacheErr}
+			message := &sarama.ConsumerMessage{Topic: "cdc.public.orders", Key: []byte(`{"id":"o1"}`), Value: tt.value}
+
+			err := NewOrderCacheConsumer(cache).Handle()(context.Background(), message)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(cache.invalidated, tt.wantInvalidated) {
+				t.Errorf("invalidated = %v, want %v", cache.invalidated, tt.wantInvalidated)
+			}
+		})
+	}
+}
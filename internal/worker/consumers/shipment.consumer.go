@@ -2,6 +2,7 @@ package consumers
 
 import (
 	"context"
+	"errors"
 
 	"go1/internal/shared/order/domain"
 	"go1/pkg/kafka"
@@ -13,12 +14,14 @@ import (
 type ShipmentConsumer struct {
 	temporalClient client.Client
 	repo           domain.OrderRepository
+	eventOptions   kafka.EventOptions
 }
 
-func NewShipmentConsumer(temporalClient client.Client, repo domain.OrderRepository) *ShipmentConsumer {
+func NewShipmentConsumer(temporalClient client.Client, repo domain.OrderRepository, eventOptions kafka.EventOptions) *ShipmentConsumer {
 	return &ShipmentConsumer{
 		temporalClient: temporalClient,
 		repo:           repo,
+		eventOptions:   eventOptions,
 	}
 }
 
@@ -28,11 +31,17 @@ type ShipmentEvent struct {
 }
 
 func (h *ShipmentConsumer) Handle() kafka.MessageHandler {
-	return kafka.HandleJSON(func(ctx context.Context, event ShipmentEvent, meta *kafka.MessageMetadata) error {
+	return kafka.HandleEventWithOptions(h.eventOptions, func(ctx context.Context, envelope kafka.Event[ShipmentEvent], meta *kafka.MessageMetadata) error {
+		event := envelope.Data
+
 		// Get Order to find WorkflowID
 		order, err := h.repo.GetByID(ctx, event.OrderID)
 		if err != nil {
 			logger.Log.Error("Failed to get order", logger.Field{Key: "error", Value: err})
+			if errors.Is(err, domain.ErrOrderNotFound) {
+				// The order will not appear on retry
+				return kafka.Permanent(err)
+			}
 			return err
 		}
 
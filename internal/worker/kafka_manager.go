@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go1/config"
@@ -11,10 +12,14 @@ import (
 	"github.com/IBM/sarama"
 )
 
+// ErrUnknownTopic is returned for topics the worker does not consume
+var ErrUnknownTopic = errors.New("topic is not consumed by this worker")
+
 type KafkaManager struct {
-	consumer *kafka.Consumer
-	handlers map[string]kafka.MessageHandler
-	config   *config.Config
+	consumer   *kafka.Consumer
+	handlers   map[string]kafka.MessageHandler
+	config     *config.Config
+	groupAdmin *kafka.GroupAdmin // Set by UseGroupAdmin; needed for offsets and resets
 }
 
 func NewKafkaManager(cfg *config.Config) *KafkaManager {
@@ -46,3 +51,82 @@ func (m *KafkaManager) routeMessage(ctx context.Context, message *sarama.Consume
 	}
 	return handler(ctx, message)
 }
+
+// UseGroupAdmin enables reading and resetting the group's offsets
+func (m *KafkaManager) UseGroupAdmin(admin *kafka.GroupAdmin) {
+	m.groupAdmin = admin
+}
+
+// ConsumerStatus is this worker's consumer state and the group's offsets
+type ConsumerStatus struct {
+	GroupID string `json:"groupId"`
+	kafka.ConsumerState
+	Offsets []kafka.PartitionOffset `json:"offsets,omitempty"`
+}
+
+// Status returns this member's assignment, pauses and, with a group admin, the group's offsets
+func (m *KafkaManager) Status() (ConsumerStatus, error) {
+	status := ConsumerStatus{GroupID: m.config.Kafka.GroupID, ConsumerState: m.consumer.State()}
+	if m.groupAdmin == nil {
+		return status, nil
+	}
+	offsets, err := m.groupAdmin.Offsets(m.consumer.Topics())
+	if err != nil {
+		return status, err
+	}
+	status.Offsets = offsets
+	return status, nil
+}
+
+// Pause stops processing partitions of a topic (all when none are given) on this worker
+func (m *KafkaManager) Pause(topic string, partitions []int32) error {
+	if err := m.checkTopic(topic); err != nil {
+		return err
+	}
+	m.consumer.Pause(topic, partitions)
+	return nil
+}
+
+// Resume undoes Pause on this worker
+func (m *KafkaManager) Resume(topic string, partitions []int32) error {
+	if err := m.checkTopic(topic); err != nil {
+		return err
+	}
+	m.consumer.Resume(topic, partitions)
+	return nil
+}
+
+// Stop makes this worker leave the consumer group until Start
+func (m *KafkaManager) Stop() {
+	m.consumer.Stop()
+}
+
+// Start makes this worker rejoin the consumer group
+func (m *KafkaManager) Start() {
+	m.consumer.Start()
+}
+
+// ResetOffsets moves the group's offsets on a topic; every worker of the group must be stopped
+func (m *KafkaManager) ResetOffsets(topic string, partitions []int32, reset kafka.OffsetReset) ([]kafka.PartitionOffset, error) {
+	if err := m.checkTopic(topic); err != nil {
+		return nil, err
+	}
+	if m.groupAdmin == nil {
+		return nil, fmt.Errorf("group admin not configured")
+	}
+	return m.groupAdmin.ResetOffsets(topic, partitions, reset)
+}
+
+// checkTopic rejects topics this worker does not consume
+func (m *KafkaManager) checkTopic(topic string) error {
+	if _, ok := m.handlers[topic]; !ok {
+		return fmt.Errorf("%s: %w", topic, ErrUnknownTopic)
+	}
+	return nil
+}
+
+func (m *KafkaManager) Close() {
+	if m.groupAdmin != nil {
+		m.groupAdmin.Close()
+	}
+}
@@ -3,12 +3,17 @@ package worker
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"go1/config"
+	"go1/pkg/kafka"
 	"go1/pkg/logger"
+	"go1/pkg/outbox"
 	"go1/pkg/postgres"
 	"go1/pkg/redis"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.temporal.io/sdk/client"
 	tWorker "go.temporal.io/sdk/worker"
 )
@@ -21,6 +26,12 @@ type Worker struct {
 	config         *config.Config
 	temporalClient client.Client
 	temporalWorker tWorker.Worker
+	producer       *kafka.KafkaProducer
+	outboxRelay    *outbox.Relay
+	dlq            *kafka.DLQ
+	adminServer    *http.Server
+	metricsServer  *http.Server
+	tracerProvider *sdktrace.TracerProvider
 }
 
 // New creates and initializes a new worker application
@@ -30,6 +41,9 @@ func New(cfg *config.Config) (*Worker, error) {
 	if err := worker.initLogger(); err != nil {
 		return nil, err
 	}
+	if err := worker.initTelemetry(); err != nil {
+		return nil, err
+	}
 	if err := worker.initPostgres(); err != nil {
 		return nil, err
 	}
@@ -42,9 +56,18 @@ func New(cfg *config.Config) (*Worker, error) {
 	if err := worker.initTemporalWorker(); err != nil {
 		return nil, err
 	}
+	if err := worker.initOutboxRelay(); err != nil {
+		return nil, err
+	}
 	if err := worker.initKafkaManager(); err != nil {
 		return nil, err
 	}
+	if err := worker.initAdminServer(); err != nil {
+		return nil, err
+	}
+	if err := worker.initMetricsServer(); err != nil {
+		return nil, err
+	}
 
 	return worker, nil
 }
@@ -57,11 +80,55 @@ func (w *Worker) Run(ctx context.Context) error {
 		}
 		defer w.temporalWorker.Stop()
 	}
+	if w.outboxRelay != nil {
+		relayCtx, cancelRelay := context.WithCancel(ctx)
+		relayDone := make(chan struct{})
+		go func() {
+			defer close(relayDone)
+			w.outboxRelay.Run(relayCtx)
+		}()
+		// Let an in-flight batch finish before the producer is closed
+		defer func() {
+			cancelRelay()
+			<-relayDone
+		}()
+	}
+	if w.adminServer != nil {
+		defer serve("Worker admin server", w.adminServer)()
+	}
+	if w.metricsServer != nil {
+		defer serve("Worker metrics server", w.metricsServer)()
+	}
 	return w.kafkaManager.Run(ctx)
 }
 
+// serve starts an HTTP server in the background and returns its graceful shutdown
+func serve(name string, server *http.Server) func() {
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Log.Error(name+" failed", logger.Field{Key: "error", Value: err})
+		}
+	}()
+	logger.Log.Info(name+" is running", logger.Field{Key: "addr", Value: server.Addr})
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}
+}
+
 // Close gracefully shuts down the worker application
 func (w *Worker) Close() {
+	if w.kafkaManager != nil {
+		w.kafkaManager.Close()
+	}
+	if w.dlq != nil {
+		w.dlq.Close()
+	}
+	if w.producer != nil {
+		w.producer.Close()
+	}
 	if w.temporalClient != nil {
 		w.temporalClient.Close()
 	}
@@ -71,5 +138,10 @@ func (w *Worker) Close() {
 	if w.redis != nil {
 		w.redis.Close()
 	}
+	if w.tracerProvider != nil {
+		if err := w.tracerProvider.Shutdown(context.Background()); err != nil {
+			logger.Log.Warn("Failed to shutdown tracer provider", logger.Field{Key: "error", Value: err})
+		}
+	}
 	logger.Log.Info("Worker application closed")
 }
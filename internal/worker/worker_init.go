@@ -1,14 +1,25 @@
 package worker
 
 import (
+	"fmt"
+	"net/http"
+	"time"
+
 	"go1/internal/shared/order/activity"
+	"go1/internal/shared/order/domain"
 	"go1/internal/shared/order/infrastructure/repository"
 	"go1/internal/shared/order/workflow"
+	"go1/pkg/kafka"
 	"go1/pkg/logger"
+	"go1/pkg/outbox"
 	"go1/pkg/postgres"
 	"go1/pkg/redis"
+	"go1/pkg/telemetry"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.temporal.io/sdk/client"
+	temporalotel "go.temporal.io/sdk/contrib/opentelemetry"
+	"go.temporal.io/sdk/interceptor"
 	tWorker "go.temporal.io/sdk/worker"
 )
 
@@ -46,9 +57,27 @@ func (w *Worker) initRedis() error {
 	return nil
 }
 
+func (w *Worker) initTelemetry() error {
+	tp, err := telemetry.InitTracer(w.config.App.Name+"-worker", w.config.Jaeger.Endpoint)
+	if err != nil {
+		logger.Log.Warn("Failed to initialize OpenTelemetry", logger.Field{Key: "error", Value: err})
+		return nil // Don't fail worker startup if tracing fails
+	}
+	w.tracerProvider = tp
+	logger.Log.Info("OpenTelemetry initialized", logger.Field{Key: "endpoint", Value: w.config.Jaeger.Endpoint})
+	return nil
+}
+
 func (w *Worker) initTemporal() error {
+	// Carries the Kafka consumer span into workflow starts/signals, workflows and activities
+	tracingInterceptor, err := temporalotel.NewTracingInterceptor(temporalotel.TracerOptions{})
+	if err != nil {
+		return err
+	}
+
 	c, err := client.Dial(client.Options{
-		HostPort: w.config.Temporal.HostPort,
+		HostPort:     w.config.Temporal.HostPort,
+		Interceptors: []interceptor.ClientInterceptor{tracingInterceptor},
 	})
 	if err != nil {
 		return err
@@ -70,13 +99,56 @@ func (w *Worker) initTemporalWorker() error {
 	return nil
 }
 
+// initOutboxRelay always runs: the order repository writes every domain event to the
+// outbox, and order workflows are only started once those events reach Kafka.
+func (w *Worker) initOutboxRelay() error {
+	producer, err := kafka.NewProducer(w.config.Kafka)
+	if err != nil {
+		return err
+	}
+	if err := producer.UseCloudEvents(w.config.Kafka.CloudEvents); err != nil {
+		producer.Close()
+		return err
+	}
+	if w.config.Kafka.SchemaRegistry.File != "" {
+		schemas, err := kafka.LoadSchemaRegistry(w.config.Kafka.SchemaRegistry.File)
+		if err != nil {
+			producer.Close()
+			return err
+		}
+		producer.UseSchemaRegistry(schemas)
+	}
+	w.producer = producer
+
+	relay, err := outbox.NewRelay(w.postgres.Pool, producer, outbox.RelayOptions{
+		Topics: map[string]string{
+			domain.AggregateTypeOrder: w.config.Kafka.Topics.OrderDomainEvents,
+		},
+		PollInterval: time.Duration(w.config.Outbox.PollIntervalMs) * time.Millisecond,
+		BatchSize:    w.config.Outbox.BatchSize,
+		Retention:    time.Duration(w.config.Outbox.RetentionHours) * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+	w.outboxRelay = relay
+	return nil
+}
+
 func (w *Worker) initKafkaManager() error {
+	dedup, err := w.newDedupStore()
+	if err != nil {
+		return err
+	}
+
 	// Register handlers here with explicit dependencies
 	// Note: Retry configuration for topics is automatically loaded by AddTopic
 	manager, err := NewWorkerBuilder(w.config).
+		WithDedup(dedup).
 		WithShipmentEvents(w.postgres, w.temporalClient).
 		WithDispatchEvents(w.postgres, w.temporalClient).
 		WithOrderEvents(w.temporalClient).
+		WithOrderCacheInvalidation(w.redis).
 		Build()
 
 	if err != nil {
@@ -86,3 +158,63 @@ func (w *Worker) initKafkaManager() error {
 	w.kafkaManager = manager
 	return nil
 }
+
+// newDedupStore returns the configured processed-message store, or nil when deduplication is off
+func (w *Worker) newDedupStore() (kafka.DedupStore, error) {
+	ttl := time.Duration(w.config.Kafka.Dedup.TTLHours) * time.Hour
+	claimTimeout := time.Duration(w.config.Kafka.Dedup.ClaimTimeoutSeconds) * time.Second
+	switch w.config.Kafka.Dedup.Store {
+	case "":
+		return nil, nil
+	case "redis":
+		return kafka.NewRedisDedupStore(w.redis, w.config.Kafka.GroupID, ttl, claimTimeout), nil
+	case "postgres":
+		return kafka.NewPostgresDedupStore(w.postgres.Pool, w.config.Kafka.GroupID, ttl, claimTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown kafka.dedup.store %q", w.config.Kafka.Dedup.Store)
+	}
+}
+
+func (w *Worker) initAdminServer() error {
+	if !w.config.WorkerAdmin.Enabled {
+		return nil
+	}
+	if err := validateAdminTokens(w.config.WorkerAdmin.Tokens); err != nil {
+		return err
+	}
+
+	dlq, err := kafka.NewDLQ(w.config.Kafka, kafka.DLQOptions{
+		DLQTopicSuffix: w.config.Kafka.Retry.DLQSuffix,
+		AuditTopic:     w.config.Kafka.Retry.ReplayAuditTopic,
+	})
+	if err != nil {
+		return err
+	}
+	w.dlq = dlq
+
+	groupAdmin, err := kafka.NewGroupAdmin(w.config.Kafka)
+	if err != nil {
+		return err
+	}
+	w.kafkaManager.UseGroupAdmin(groupAdmin)
+
+	w.adminServer = &http.Server{
+		Addr:    ":" + w.config.WorkerAdmin.Port,
+		Handler: newAdminRouter(w.config.WorkerAdmin.Tokens, dlq, w.kafkaManager),
+	}
+	return nil
+}
+
+func (w *Worker) initMetricsServer() error {
+	if !w.config.WorkerMetrics.Enabled {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	w.metricsServer = &http.Server{
+		Addr:    ":" + w.config.WorkerMetrics.Port,
+		Handler: mux,
+	}
+	return nil
+}
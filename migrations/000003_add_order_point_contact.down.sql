@@ -0,0 +1,4 @@
+ALTER TABLE order_points
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS contact_name;
@@ -0,0 +1,4 @@
+ALTER TABLE order_points
+    ADD COLUMN IF NOT EXISTS contact_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS phone TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT ''; -- Driver instructions, e.g. 'gate 3', 'call on arrival'
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_orders_driver_id;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS driver_id,
+    DROP COLUMN IF EXISTS customer_id,
+    DROP COLUMN IF EXISTS creator_role;
@@ -0,0 +1,7 @@
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS creator_role TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS customer_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS driver_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders(customer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_driver_id ON orders(driver_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_partner_id;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS partner_id;
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS partner_id TEXT NOT NULL DEFAULT ''; -- '' is the default (first-party) tenant
+
+CREATE INDEX IF NOT EXISTS idx_orders_partner_id ON orders(partner_id);
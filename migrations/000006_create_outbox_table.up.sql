@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    seq BIGSERIAL PRIMARY KEY, -- Relay order: events are published in insertion order
+    id TEXT NOT NULL UNIQUE,   -- Event ID
+    aggregate_type TEXT NOT NULL, -- e.g., 'order'
+    aggregate_id TEXT NOT NULL,   -- Used as the Kafka message key to keep per-aggregate ordering
+    event_type TEXT NOT NULL,     -- e.g., 'OrderCreated'
+    event_version INT NOT NULL DEFAULT 1,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_unpublished ON outbox(seq) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_published_at ON outbox(published_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS processed_messages (
+    consumer_group TEXT NOT NULL,
+    message_id TEXT NOT NULL, -- 'event:<id>' or 'offset:<topic>/<partition>/<offset>'
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consumer_group, message_id)
+);
+
+-- Rows older than the dedup TTL are ignored and can be deleted
+CREATE INDEX idx_processed_messages_processed_at ON processed_messages(processed_at);
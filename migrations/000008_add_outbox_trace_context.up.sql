@@ -0,0 +1,3 @@
+-- W3C trace context of the request that wrote the event, continued by the relay when publishing
+ALTER TABLE outbox ADD COLUMN traceparent TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox ADD COLUMN tracestate TEXT NOT NULL DEFAULT '';
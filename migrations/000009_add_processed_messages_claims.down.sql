@@ -0,0 +1,2 @@
+DELETE FROM processed_messages WHERE claimed_until IS NOT NULL;
+ALTER TABLE processed_messages DROP COLUMN IF EXISTS claimed_until;
@@ -0,0 +1,2 @@
+-- Set while a consumer is handling the message; NULL once it has been processed
+ALTER TABLE processed_messages ADD COLUMN claimed_until TIMESTAMP WITH TIME ZONE;
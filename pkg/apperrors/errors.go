@@ -29,3 +29,11 @@ const (
 func NewServiceDisabledError(message string) *AppError {
 	return NewAppError(http.StatusBadRequest, message, http.StatusBadRequest)
 }
+
+func NewNotFoundError(message string) *AppError {
+	return NewAppError(http.StatusNotFound, message, http.StatusNotFound)
+}
+
+func NewForbiddenError(message string) *AppError {
+	return NewAppError(http.StatusForbidden, message, http.StatusForbidden)
+}
@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go1/pkg/logger"
+)
+
+// minRefetchInterval throttles JWKS refetches triggered by unknown key IDs
+const minRefetchInterval = 30 * time.Second
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKS holds the public keys used to verify token signatures, indexed by key ID.
+// Keys loaded from a URL are refreshed periodically and when an unknown key ID is seen.
+type JWKS struct {
+	mu        sync.RWMutex
+	fetchMu   sync.Mutex
+	keys      map[string]crypto.PublicKey
+	url       string
+	refresh   time.Duration
+	lastFetch time.Time
+	client    *http.Client
+}
+
+// NewJWKSFromFile loads a static key set from a local JWKS file.
+func NewJWKSFromFile(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return &JWKS{keys: keys}, nil
+}
+
+// NewJWKSFromURL fetches the key set from url and keeps it fresh every refresh interval.
+func NewJWKSFromURL(url string, refresh time.Duration) (*JWKS, error) {
+	k := &JWKS{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := k.fetch(context.Background()); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Key returns the public key for kid. An empty kid matches the only key in a single-key set.
+func (k *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if k.url != "" && k.refresh > 0 && k.since() > k.refresh {
+		k.refetch(ctx, k.refresh)
+	}
+
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+
+	// The signing key may have been rotated: refetch once, throttled
+	if k.url != "" && k.since() > minRefetchInterval {
+		k.refetch(ctx, minRefetchInterval)
+		if key, ok := k.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (k *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+func (k *JWKS) since() time.Duration {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return time.Since(k.lastFetch)
+}
+
+// refetch fetches the key set unless another caller did so within maxAge
+func (k *JWKS) refetch(ctx context.Context, maxAge time.Duration) {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	if k.since() <= maxAge {
+		return
+	}
+	if err := k.fetch(ctx); err != nil {
+		// Keep serving the previous keys
+		logger.Log.Warn("Failed to refresh JWKS",
+			logger.Field{Key: "url", Value: k.url},
+			logger.Field{Key: "error", Value: err})
+	}
+}
+
+func (k *JWKS) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		k.touch()
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		k.touch()
+		return fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		k.touch()
+		return fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		k.touch()
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.lastFetch = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// touch records a fetch attempt so failures are throttled like successes
+func (k *JWKS) touch() {
+	k.mu.Lock()
+	k.lastFetch = time.Now()
+	k.mu.Unlock()
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no signing keys")
+	}
+	return keys, nil
+}
+
+func parseJWK(jwk jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
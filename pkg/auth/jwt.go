@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go1/config"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrMissingToken  = errors.New("missing bearer token")
+	errMissingUserID = errors.New("missing user id claim")
+)
+
+// JWTVerifier validates bearer tokens and maps their claims onto a request.UserContext.
+type JWTVerifier struct {
+	keys   *JWKS
+	parser *jwt.Parser
+	claims config.JWTClaimsConfig
+}
+
+// NewJWTVerifier builds a verifier from config, loading keys from the JWKS file or URL.
+func NewJWTVerifier(cfg config.JWTConfig) (*JWTVerifier, error) {
+	var (
+		keys *JWKS
+		err  error
+	)
+	switch {
+	case cfg.JWKSFile != "":
+		keys, err = NewJWKSFromFile(cfg.JWKSFile)
+	case cfg.JWKSURL != "":
+		keys, err = NewJWKSFromURL(cfg.JWKSURL, time.Duration(cfg.JWKSRefreshSeconds)*time.Second)
+	default:
+		return nil, fmt.Errorf("auth.jwt requires jwksFile or jwksUrl")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	algorithms := cfg.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{"RS256", "ES256"}
+	}
+	for _, alg := range algorithms {
+		if !strings.HasPrefix(alg, "RS") && !strings.HasPrefix(alg, "ES") {
+			return nil, fmt.Errorf("unsupported JWT algorithm %q", alg)
+		}
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(algorithms),
+		jwt.WithLeeway(time.Duration(cfg.ClockSkewSeconds) * time.Second),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if len(cfg.Audience) > 0 {
+		opts = append(opts, jwt.WithAudience(cfg.Audience...))
+	}
+
+	return &JWTVerifier{
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+		claims: cfg.Claims,
+	}, nil
+}
+
+// Verify checks the token signature, issuer, audience and expiry and returns the caller identity.
+func (v *JWTVerifier) Verify(ctx context.Context, tokenString string) (*request.UserContext, error) {
+	if tokenString == "" {
+		return nil, ErrMissingToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	userID := stringClaim(claims, v.claims.UserID, "sub")
+	if userID == "" {
+		return nil, fmt.Errorf("invalid token: %w", errMissingUserID)
+	}
+
+	return &request.UserContext{
+		UserID:    userID,
+		Role:      utils.UserRole(stringClaim(claims, v.claims.Role, "role")),
+		Platform:  stringClaim(claims, v.claims.Platform, "platform"),
+		PartnerID: stringClaim(claims, v.claims.PartnerID, "partner_id"),
+	}, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header value
+func BearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+func stringClaim(claims jwt.MapClaims, name, fallback string) string {
+	if name == "" {
+		name = fallback
+	}
+	value, _ := claims[name].(string)
+	return value
+}
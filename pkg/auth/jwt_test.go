@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go1/config"
+	"go1/pkg/logger"
+	"go1/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyServer serves a JWKS that tests can rotate
+type keyServer struct {
+	mu      sync.Mutex
+	keys    []jsonWebKey
+	fetches atomic.Int32
+}
+
+func (s *keyServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.fetches.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	json.NewEncoder(w).Encode(jsonWebKeySet{Keys: s.keys})
+}
+
+func (s *keyServer) add(key jsonWebKey) {
+	s.mu.Lock()
+	s.keys = append(s.keys, key)
+	s.mu.Unlock()
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jsonWebKey {
+	return jsonWebKey{Kid: kid, Kty: "EC", Use: "sig", Crv: "P-256",
+		X: base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y: base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32)))}
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{Kid: kid, Kty: "RSA", Use: "sig",
+		N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":        "cust_123",
+			"role":       "user_app",
+			"partner_id": "acme",
+			"iss":        "https://id.example.com",
+			"aud":        "go1",
+			"iat":        now.Add(-time.Minute).Unix(),
+			"exp":        now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+	sign := func(method jwt.SigningMethod, kid string, key any, c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, c)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name   string
+		token  func() string
+		rotate bool // Publish the rotated key and allow a refetch before verifying
+		want   *wantUser
+		errIs  error
+	}{
+		{
+			name:  "valid ES256",
+			token: func() string { return sign(jwt.SigningMethodES256, "ec", ecKey, claims(nil)) },
+			want:  &wantUser{id: "cust_123", role: utils.UserRoleCustomer, partner: "acme"},
+		},
+		{
+			name:  "valid RS256",
+			token: func() string { return sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims(nil)) },
+			want:  &wantUser{id: "cust_123", role: utils.UserRoleCustomer, partner: "acme"},
+		},
+		{
+			name:  "algorithm not allowed",
+			token: func() string { return sign(jwt.SigningMethodHS256, "ec", []byte("secret"), claims(nil)) },
+			errIs: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name: "none algorithm",
+			token: func() string {
+				return sign(jwt.SigningMethodNone, "ec", jwt.UnsafeAllowNoneSignatureType, claims(nil))
+			},
+			errIs: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:  "signed by another key",
+			token: func() string { return sign(jwt.SigningMethodES256, "ec", rotatedKey, claims(nil)) },
+			errIs: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				return sign(jwt.SigningMethodES256, "ec", ecKey, claims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}))
+			},
+			errIs: jwt.ErrTokenExpired,
+		},
+		{
+			name: "expired within clock skew",
+			token: func() string {
+				return sign(jwt.SigningMethodES256, "ec", ecKey, claims(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}))
+			},
+			want: &wantUser{id: "cust_123", role: utils.UserRoleCustomer, partner: "acme"},
+		},
+		{
+			name: "missing exp",
+			token: func() string {
+				return sign(jwt.SigningMethodES256, "ec", ecKey, claims(jwt.MapClaims{"exp": nil}))
+			},
+			errIs: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				return sign(jwt.SigningMethodES256, "ec", ecKey, claims(jwt.MapClaims{"iss": "https://evil.example.com"}))
+			},
+			errIs: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				return sign(jwt.SigningMethodES256, "ec", ecKey, claims(jwt.MapClaims{"aud": "other-service"}))
+			},
+			errIs: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:  "unknown kid",
+			token: func() string { return sign(jwt.SigningMethodES256, "missing", ecKey, claims(nil)) },
+			errIs: jwt.ErrTokenUnverifiable,
+		},
+		{
+			name:   "unknown kid refetched after rotation",
+			token:  func() string { return sign(jwt.SigningMethodES256, "rotated", rotatedKey, claims(nil)) },
+			rotate: true,
+			want:   &wantUser{id: "cust_123", role: utils.UserRoleCustomer, partner: "acme"},
+		},
+		{
+			name: "missing sub",
+			token: func() string {
+				return sign(jwt.SigningMethodES256, "ec", ecKey, claims(jwt.MapClaims{"sub": nil}))
+			},
+			errIs: errMissingUserID,
+		},
+		{
+			name:  "empty token",
+			token: func() string { return "" },
+			errIs: ErrMissingToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &keyServer{keys: []jsonWebKey{ecJWK("ec", &ecKey.PublicKey), rsaJWK("rsa", &rsaKey.PublicKey)}}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			verifier, err := NewJWTVerifier(config.JWTConfig{
+				JWKSURL:          ts.URL,
+				Issuer:           "https://id.example.com",
+				Audience:         []string{"go1"},
+				Algorithms:       []string{"RS256", "ES256"},
+				ClockSkewSeconds: 30,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.rotate {
+				server.add(ecJWK("rotated", &rotatedKey.PublicKey))
+				// Past the refetch throttle
+				verifier.keys.mu.Lock()
+				verifier.keys.lastFetch = time.Now().Add(-2 * minRefetchInterval)
+				verifier.keys.mu.Unlock()
+			}
+
+			user, err := verifier.Verify(context.Background(), tt.token())
+			if tt.errIs != nil {
+				if !errors.Is(err, tt.errIs) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.errIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if user.UserID != tt.want.id || user.Role != tt.want.role || user.PartnerID != tt.want.partner {
+				t.Fatalf("Verify() = %+v, want %+v", user, tt.want)
+			}
+			if tt.rotate && server.fetches.Load() != 2 {
+				t.Fatalf("JWKS fetched %d times, want 2", server.fetches.Load())
+			}
+		})
+	}
+}
+
+type wantUser struct {
+	id      string
+	role    utils.UserRole
+	partner string
+}
+
+func TestJWKSThrottlesUnknownKidRefetch(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &keyServer{keys: []jsonWebKey{ecJWK("ec", &key.PublicKey)}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	keys, err := NewJWKSFromURL(ts.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if _, err := keys.Key(context.Background(), "unknown"); err == nil {
+			t.Fatal("Key() succeeded for an unknown kid")
+		}
+	}
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", got)
+	}
+}
@@ -0,0 +1,228 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrProducerClosed is returned when publishing after Close
+var ErrProducerClosed = errors.New("kafka producer is closed")
+
+// defaultMaxInFlight bounds the async buffer when kafka.producer.async.maxInFlight is not set
+const defaultMaxInFlight = 10000
+
+// DeliveryReport is the outcome of an asynchronous publish
+type DeliveryReport struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Err       error
+}
+
+// DeliveryCallback is called once the broker acknowledged or rejected the message.
+// It runs on the producer's delivery goroutine and must not block.
+type DeliveryCallback func(DeliveryReport)
+
+// Delivery is the future result of PublishAsync
+type Delivery struct {
+	done   chan struct{}
+	report DeliveryReport
+}
+
+// Done is closed once the delivery report is available
+func (d *Delivery) Done() <-chan struct{} {
+	return d.done
+}
+
+// Wait blocks until the message is delivered or ctx ends, and returns the delivery error
+func (d *Delivery) Wait(ctx context.Context) (DeliveryReport, error) {
+	select {
+	case <-d.done:
+		return d.report, d.report.Err
+	case <-ctx.Done():
+		return DeliveryReport{}, ctx.Err()
+	}
+}
+
+// pendingDelivery travels with the message through sarama as its metadata
+type pendingDelivery struct {
+	delivery *Delivery
+	callback DeliveryCallback
+	span     trace.Span // Set by PublishAsync; synchronous publishes record their own span and metrics
+	start    time.Time
+	size     int
+}
+
+// asyncSender feeds a sarama.AsyncProducer. At most cap(slots) messages are buffered or
+// awaiting acknowledgement; senders block beyond that, so a slow broker slows callers down
+// instead of growing memory.
+type asyncSender struct {
+	producer  sarama.AsyncProducer
+	slots     chan struct{}
+	mu        sync.RWMutex // Held for reading while sending, for writing while closing
+	closed    bool
+	delivered sync.WaitGroup
+}
+
+func newAsyncSender(producer sarama.AsyncProducer, maxInFlight int) *asyncSender {
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+	s := &asyncSender{
+		producer: producer,
+		slots:    make(chan struct{}, maxInFlight),
+	}
+
+	s.delivered.Add(2)
+	go func() {
+		defer s.delivered.Done()
+		for msg := range producer.Successes() {
+			s.complete(msg, nil)
+		}
+	}()
+	go func() {
+		defer s.delivered.Done()
+		for err := range producer.Errors() {
+			s.complete(err.Msg, err.Err)
+		}
+	}()
+	return s
+}
+
+// send queues msg, blocking while the in-flight buffer is full or until ctx ends
+func (s *asyncSender) send(ctx context.Context, msg *sarama.ProducerMessage, pending *pendingDelivery) error {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		<-s.slots
+		return ErrProducerClosed
+	}
+
+	msg.Metadata = pending
+	select {
+	case s.producer.Input() <- msg:
+		return nil
+	case <-ctx.Done():
+		<-s.slots
+		return ctx.Err()
+	}
+}
+
+func (s *asyncSender) complete(msg *sarama.ProducerMessage, err error) {
+	deliver(msg, err)
+	<-s.slots
+}
+
+// deliver records the outcome of a message and resolves its Delivery
+func deliver(msg *sarama.ProducerMessage, err error) {
+	pending := msg.Metadata.(*pendingDelivery)
+	if pending.span != nil {
+		observePublish(msg.Topic, pending.start, pending.size, err)
+		endProducerSpan(pending.span, msg.Partition, msg.Offset, err)
+		if err != nil {
+			logger.Log.Error("❌ Failed to deliver message",
+				logger.Field{Key: "topic", Value: msg.Topic},
+				logger.Field{Key: "error", Value: err})
+		} else {
+			logger.Log.Debug("📤 Message delivered",
+				logger.Field{Key: "topic", Value: msg.Topic},
+				logger.Field{Key: "partition", Value: msg.Partition},
+				logger.Field{Key: "offset", Value: msg.Offset})
+		}
+	}
+
+	pending.delivery.report = DeliveryReport{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset, Err: err}
+	// The callback runs first so waiters observe its effects
+	if pending.callback != nil {
+		pending.callback(pending.delivery.report)
+	}
+	close(pending.delivery.done)
+}
+
+// flush waits until every message sent so far has been delivered. Sends block meanwhile.
+func (s *asyncSender) flush(ctx context.Context) error {
+	acquired := 0
+	defer func() {
+		for ; acquired > 0; acquired-- {
+			<-s.slots
+		}
+	}()
+	for acquired < cap(s.slots) {
+		select {
+		case s.slots <- struct{}{}:
+			acquired++
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// close rejects new sends, flushes buffered messages and waits for their delivery reports
+func (s *asyncSender) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.producer.AsyncClose()
+	s.delivered.Wait()
+}
+
+// PublishAsync queues a message and returns without waiting for the broker. The result is
+// reported through the returned Delivery and, if given, the callback. When the in-flight buffer
+// is full it blocks until a slot frees up or ctx ends. Requires kafka.producer.async.enabled;
+// otherwise the message is sent synchronously and the Delivery is already done.
+func (k *KafkaProducer) PublishAsync(ctx context.Context, topic string, key, value []byte, headers []sarama.RecordHeader, callback ...DeliveryCallback) (*Delivery, error) {
+	pending := &pendingDelivery{
+		delivery: &Delivery{done: make(chan struct{})},
+		start:    time.Now(),
+		size:     len(key) + len(value),
+	}
+	if len(callback) > 0 {
+		pending.callback = callback[0]
+	}
+
+	span, headers := startProducerSpan(ctx, topic, headers)
+	pending.span = span
+	msg := &sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.ByteEncoder(key),
+		Value:   sarama.ByteEncoder(value),
+		Headers: headers,
+	}
+
+	if k.async == nil {
+		partition, offset, err := k.producer.SendMessage(msg)
+		msg.Partition, msg.Offset, msg.Metadata = partition, offset, pending
+		deliver(msg, err)
+		return pending.delivery, nil
+	}
+
+	if err := k.async.send(ctx, msg, pending); err != nil {
+		observePublish(topic, pending.start, pending.size, err)
+		endProducerSpan(span, 0, 0, err)
+		return nil, err
+	}
+	return pending.delivery, nil
+}
+
+// Flush waits until all messages published so far have been delivered. No-op in sync mode.
+func (k *KafkaProducer) Flush(ctx context.Context) error {
+	if k.async == nil {
+		return nil
+	}
+	return k.async.flush(ctx)
+}
@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+)
+
+func newTestAsyncProducer(t *testing.T, maxInFlight int) (*KafkaProducer, *mocks.AsyncProducer) {
+	t.Helper()
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+	mock := mocks.NewAsyncProducer(t, config)
+	return &KafkaProducer{async: newAsyncSender(mock, maxInFlight)}, mock
+}
+
+func TestPublishAsyncDeliveryReports(t *testing.T) {
+	errBroker := errors.New("broker unavailable")
+
+	tests := []struct {
+		name    string
+		fail    bool
+		wantErr error
+	}{
+		{name: "delivered"},
+		{name: "rejected", fail: true, wantErr: errBroker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer, mock := newTestAsyncProducer(t, 2)
+			defer producer.Close()
+			if tt.fail {
+				mock.ExpectInputAndFail(errBroker)
+			} else {
+				mock.ExpectInputAndSucceed()
+			}
+
+			var reported DeliveryReport
+			delivery, err := producer.PublishAsync(context.Background(), "orders", []byte("k"), []byte("v"), nil, func(report DeliveryReport) {
+				reported = report
+			})
+			if err != nil {
+				t.Fatalf("PublishAsync() error = %v", err)
+			}
+
+			report, err := delivery.Wait(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Wait() error = %v, want %v", err, tt.wantErr)
+			}
+			if report.Topic != "orders" || reported != report {
+				t.Errorf("callback report = %+v, delivery report = %+v", reported, report)
+			}
+			if err := producer.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if len(producer.async.slots) != 0 {
+				t.Errorf("%d slots still held after delivery", len(producer.async.slots))
+			}
+		})
+	}
+}
+
+func TestPublishAsyncBlocksWhenInFlightIsFull(t *testing.T) {
+	producer, mock := newTestAsyncProducer(t, 1)
+	defer producer.Close()
+	mock.ExpectInputAndSucceed()
+
+	// Hold the only slot as an undelivered message would
+	producer.async.slots <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := producer.PublishAsync(ctx, "orders", nil, []byte("v"), nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("PublishAsync() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	<-producer.async.slots
+	delivery, err := producer.PublishAsync(context.Background(), "orders", nil, []byte("v"), nil)
+	if err != nil {
+		t.Fatalf("PublishAsync() error = %v", err)
+	}
+	if _, err := delivery.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
+
+func TestPublishAsyncAfterClose(t *testing.T) {
+	producer, _ := newTestAsyncProducer(t, 1)
+	producer.Close()
+
+	if _, err := producer.PublishAsync(context.Background(), "orders", nil, []byte("v"), []sarama.RecordHeader{}); !errors.Is(err, ErrProducerClosed) {
+		t.Fatalf("PublishAsync() error = %v, want %v", err, ErrProducerClosed)
+	}
+}
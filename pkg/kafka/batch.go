@@ -0,0 +1,154 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go1/pkg/logger"
+	"go1/pkg/metrics"
+
+	"github.com/IBM/sarama"
+)
+
+// BatchMessageHandler processes messages of one partition together, in offset order.
+// Return a *BatchError to fail only some items; any other error fails the whole batch.
+type BatchMessageHandler func(ctx context.Context, messages []*sarama.ConsumerMessage) error
+
+// BatchConfig limits how many messages are accumulated before the handler is called
+type BatchConfig struct {
+	// MaxSize flushes the batch once it holds this many messages
+	MaxSize int
+	// MaxWait flushes a non-empty batch this long after its first message arrived
+	MaxWait time.Duration
+}
+
+// BatchTopic registers a batch handler for a topic
+type BatchTopic struct {
+	Handler BatchMessageHandler
+	Config  BatchConfig
+}
+
+// BatchError reports the items of a batch that failed; the others are committed.
+// Failed items go through the topic's retry/DLQ path individually.
+type BatchError struct {
+	failures map[int]error
+}
+
+func NewBatchError() *BatchError {
+	return &BatchError{failures: make(map[int]error)}
+}
+
+// Fail records the failure of the item at index in the batch
+func (e *BatchError) Fail(index int, err error) {
+	e.failures[index] = err
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d batch item(s) failed", len(e.failures))
+}
+
+// ErrOrNil returns nil when no item failed, so handlers can end with `return errs.ErrOrNil()`
+func (e *BatchError) ErrOrNil() error {
+	if len(e.failures) == 0 {
+		return nil
+	}
+	return e
+}
+
+// batchFailure returns the error of item i for the error returned by a batch handler
+func batchFailure(err error, i int) error {
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		return batchErr.failures[i]
+	}
+	return err
+}
+
+// SingleFromBatch adapts a batch handler to single messages, e.g. for retry topics where
+// items arrive one at a time after their backoff
+func SingleFromBatch(handler BatchMessageHandler) MessageHandler {
+	return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		return batchFailure(handler(ctx, []*sarama.ConsumerMessage{message}), 0)
+	}
+}
+
+// consumeBatches accumulates the partition's messages and hands them over together.
+// Offsets are marked after each batch; failed items are retried or dead-lettered on their own.
+// On session end the pending batch is left unmarked and redelivered to the next owner.
+func (h *consumerGroupHandler) consumeBatches(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, topic BatchTopic) error {
+	maxSize := topic.Config.MaxSize
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	maxWait := topic.Config.MaxWait
+	if maxWait <= 0 {
+		maxWait = time.Second
+	}
+
+	batch := make([]*sarama.ConsumerMessage, 0, maxSize)
+	var timeout <-chan time.Time
+
+	flush := func() {
+		ctx, span := startBatchSpan(session.Context(), claim.Topic(), claim.Partition(), batch)
+		start := time.Now()
+		err := topic.Handler(ctx, batch)
+		metrics.KafkaConsumerHandlerDuration.WithLabelValues(claim.Topic()).Observe(time.Since(start).Seconds())
+		for i := range batch {
+			countHandled(claim.Topic(), batchFailure(err, i))
+		}
+		if err != nil {
+			logger.Log.Error("Batch handler failed",
+				logger.Field{Key: "topic", Value: claim.Topic()},
+				logger.Field{Key: "partition", Value: claim.Partition()},
+				logger.Field{Key: "size", Value: len(batch)},
+				logger.Field{Key: "error", Value: err})
+			for i, message := range batch {
+				if itemErr := batchFailure(err, i); itemErr != nil {
+					h.consumer.handleError(ctx, message, itemErr)
+				}
+			}
+		}
+		endSpan(span, err)
+		session.MarkMessage(batch[len(batch)-1], "")
+		// The handler may keep the slice, so start a new one
+		batch = make([]*sarama.ConsumerMessage, 0, maxSize)
+		timeout = nil
+	}
+
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				logger.Log.Info("Message channel closed",
+					logger.Field{Key: "topic", Value: claim.Topic()},
+					logger.Field{Key: "partition", Value: claim.Partition()})
+				return nil
+			}
+			h.recordLag(claim, message)
+			if !h.waitWhilePaused(session, message.Topic, message.Partition) {
+				return nil
+			}
+			batch = append(batch, message)
+			if len(batch) == 1 {
+				timeout = time.After(maxWait)
+			}
+			if len(batch) >= maxSize {
+				flush()
+			}
+
+		case <-timeout:
+			if !h.waitWhilePaused(session, claim.Topic(), claim.Partition()) {
+				return nil
+			}
+			flush()
+
+		case <-session.Context().Done():
+			logger.Log.Info("Session context cancelled, exiting consume loop",
+				logger.Field{Key: "topic", Value: claim.Topic()},
+				logger.Field{Key: "partition", Value: claim.Partition()})
+			return nil
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+)
+
+func TestBatchFailure(t *testing.T) {
+	itemErr := errors.New("item failed")
+	wholeErr := errors.New("batch failed")
+	batchErr := NewBatchError()
+	batchErr.Fail(1, itemErr)
+
+	tests := []struct {
+		name  string
+		err   error
+		index int
+		want  error
+	}{
+		{name: "no error", err: nil, index: 0, want: nil},
+		{name: "whole batch failed", err: wholeErr, index: 0, want: wholeErr},
+		{name: "failed item", err: batchErr, index: 1, want: itemErr},
+		{name: "succeeded item", err: batchErr, index: 0, want: nil},
+		{name: "wrapped batch error", err: fmt.Errorf("handler: %w", batchErr), index: 1, want: itemErr},
+		{name: "wrapped succeeded item", err: Permanent(batchErr), index: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batchFailure(tt.err, tt.index); got != tt.want {
+				t.Errorf("batchFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeClaim feeds messages of one partition; other ConsumerGroupClaim methods are not used
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return "orders" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 100 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func TestConsumeBatches(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name        string
+		config      BatchConfig
+		sent        int          // Messages sent before the session ends
+		fail        func() error // Result of each handler call
+		wantBatches []int
+		wantMarked  []int64
+		wantRetried []int64 // Offsets republished to the retry topic
+	}{
+		{
+			name:        "flushes at max size and leaves the rest unmarked on session end",
+			config:      BatchConfig{MaxSize: 3, MaxWait: time.Hour},
+			sent:        5,
+			wantBatches: []int{3},
+			wantMarked:  []int64{2},
+		},
+		{
+			name:        "flushes after max wait",
+			config:      BatchConfig{MaxSize: 100, MaxWait: 10 * time.Millisecond},
+			sent:        2,
+			wantBatches: []int{2},
+			wantMarked:  []int64{1},
+		},
+		{
+			name:   "retries only the failed items",
+			config: BatchConfig{MaxSize: 3, MaxWait: time.Hour},
+			sent:   3,
+			fail: func() error {
+				errs := NewBatchError()
+				errs.Fail(1, errHandler)
+				return errs.ErrOrNil()
+			},
+			wantBatches: []int{3},
+			wantMarked:  []int64{2},
+			wantRetried: []int64{1},
+		},
+		{
+			name:        "retries every item of a failed batch",
+			config:      BatchConfig{MaxSize: 2, MaxWait: time.Hour},
+			sent:        2,
+			fail:        func() error { return errHandler },
+			wantBatches: []int{2},
+			wantMarked:  []int64{1},
+			wantRetried: []int64{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var retried []int64
+			mock := mocks.NewSyncProducer(t, nil)
+			for range tt.wantRetried {
+				mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+					if msg.Topic != "orders.retry" {
+						return fmt.Errorf("republished to %s, want orders.retry", msg.Topic)
+					}
+					value, _ := msg.Value.Encode()
+					var offset int64
+					fmt.Sscan(string(value), &offset)
+					retried = append(retried, offset)
+					return nil
+				})
+			}
+
+			h := &consumerGroupHandler{consumer: &Consumer{
+				opts: ConsumerOptions{
+					GroupID:          "test",
+					RetryTopicSuffix: ".retry",
+					DLQTopicSuffix:   ".dlq",
+					TopicRetryConfig: map[string]TopicRetryConfig{"orders": {MaxAttempts: 3, Backoff: time.Second}},
+				},
+				producer: &KafkaProducer{producer: mock},
+			}}
+
+			flushed := make(chan int, 10)
+			topic := BatchTopic{Config: tt.config, Handler: func(ctx context.Context, messages []*sarama.ConsumerMessage) error {
+				flushed <- len(messages)
+				if tt.fail != nil {
+					return tt.fail()
+				}
+				return nil
+			}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			session := &fakeSession{ctx: ctx}
+			claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, tt.sent)}
+			done := make(chan error)
+			go func() { done <- h.consumeBatches(session, claim, topic) }()
+
+			for offset := 0; offset < tt.sent; offset++ {
+				claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Offset: int64(offset), Value: []byte(fmt.Sprint(offset))}
+			}
+
+			var batches []int
+			for range tt.wantBatches {
+				select {
+				case size := <-flushed:
+					batches = append(batches, size)
+				case <-time.After(time.Second):
+					t.Fatalf("flushed batches %v, want %v", batches, tt.wantBatches)
+				}
+			}
+			// Let the loop take up the remaining messages, then end the session
+			for len(claim.messages) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("consumeBatches() error = %v", err)
+			}
+			close(flushed)
+			for size := range flushed {
+				batches = append(batches, size)
+			}
+
+			if !slices.Equal(batches, tt.wantBatches) {
+				t.Errorf("batches = %v, want %v", batches, tt.wantBatches)
+			}
+			if !slices.Equal(session.marked, tt.wantMarked) {
+				t.Errorf("marked = %v, want %v", session.marked, tt.wantMarked)
+			}
+			if !slices.Equal(retried, tt.wantRetried) {
+				t.Errorf("retried = %v, want %v", retried, tt.wantRetried)
+			}
+		})
+	}
+}
+
+func TestConsumeBatchesLeavesPendingUnmarkedWhenClaimCloses(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	h := &consumerGroupHandler{consumer: &Consumer{}}
+	called := false
+	topic := BatchTopic{Config: BatchConfig{MaxSize: 10, MaxWait: time.Hour}, Handler: func(context.Context, []*sarama.ConsumerMessage) error {
+		called = true
+		return nil
+	}}
+
+	session := &fakeSession{ctx: context.Background()}
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Offset: 0}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "orders", Offset: 1}
+	close(claim.messages)
+
+	if err := h.consumeBatches(session, claim, topic); err != nil {
+		t.Fatalf("consumeBatches() error = %v", err)
+	}
+	if called || len(session.marked) != 0 {
+		t.Errorf("handler called = %v, marked = %v; want a pending batch left for the next owner", called, session.marked)
+	}
+}
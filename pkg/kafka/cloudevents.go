@@ -0,0 +1,215 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appConfig "go1/config"
+
+	"github.com/IBM/sarama"
+)
+
+// CloudEvents 1.0 Kafka protocol binding
+// https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/bindings/kafka-protocol-binding.md
+
+// ContentMode selects how events are encoded on a topic
+type ContentMode string
+
+const (
+	// ContentModeEnvelope publishes the plain Event envelope (default)
+	ContentModeEnvelope ContentMode = ""
+	// ContentModeBinary publishes the event data as the value and attributes as ce_ headers
+	ContentModeBinary ContentMode = "binary"
+	// ContentModeStructured publishes the whole CloudEvent as application/cloudevents+json
+	ContentModeStructured ContentMode = "structured"
+)
+
+const (
+	cloudEventsSpecVersion  = "1.0"
+	cloudEventsHeaderPrefix = "ce_"
+	cloudEventsContentType  = "application/cloudevents+json"
+	jsonContentType         = "application/json"
+	headerContentType       = "content-type"
+
+	// Extension attributes carrying envelope fields that have no core CloudEvents attribute
+	ceExtensionVersion     = "eventversion"
+	ceExtensionProducer    = "producer"
+	ceExtensionTraceParent = "traceparent"
+	ceExtensionTraceState  = "tracestate"
+)
+
+// cloudEvent is the structured-mode JSON representation
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	EventVersion    string          `json:"eventversion,omitempty"`
+	Producer        string          `json:"producer,omitempty"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	TraceState      string          `json:"tracestate,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// cloudEventsModes resolves the configured content mode of a topic
+type cloudEventsModes struct {
+	source string
+	topics map[string]ContentMode
+}
+
+func newCloudEventsModes(cfg appConfig.KafkaCloudEventsConfig, defaultSource string) (*cloudEventsModes, error) {
+	modes := &cloudEventsModes{
+		source: cfg.Source,
+		topics: make(map[string]ContentMode, len(cfg.Topics)),
+	}
+	if modes.source == "" {
+		modes.source = defaultSource
+	}
+	for topic, mode := range cfg.Topics {
+		switch ContentMode(strings.ToLower(mode)) {
+		case ContentModeBinary, ContentModeStructured:
+			modes.topics[topic] = ContentMode(strings.ToLower(mode))
+		default:
+			return nil, fmt.Errorf("invalid CloudEvents content mode %q for topic %s", mode, topic)
+		}
+	}
+	return modes, nil
+}
+
+func (m *cloudEventsModes) modeFor(topic string) ContentMode {
+	if m == nil {
+		return ContentModeEnvelope
+	}
+	if mode, ok := m.topics[topic]; ok {
+		return mode
+	}
+	// Viper lowercases keys and cannot hold dots in them
+	return m.topics[strings.ReplaceAll(strings.ToLower(topic), ".", "_")]
+}
+
+// encodeCloudEvent encodes an event envelope in the given CloudEvents content mode
+func encodeCloudEvent[T any](event Event[T], source string, mode ContentMode) ([]byte, []sarama.RecordHeader, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	ce := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              event.ID,
+		Source:          source,
+		Type:            event.Type,
+		Subject:         event.AggregateID,
+		Time:            event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		DataContentType: jsonContentType,
+		EventVersion:    strconv.Itoa(event.Version),
+		Producer:        event.Producer,
+		TraceParent:     event.TraceParent,
+		TraceState:      event.TraceState,
+		Data:            data,
+	}
+
+	// Standard headers are kept in both modes so HandleEventTypes can route without decoding
+	headers := eventHeaders(event.ID, event.Type, event.Version)
+
+	if mode == ContentModeStructured {
+		value, err := json.Marshal(ce)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal cloud event: %w", err)
+		}
+		headers = append(headers, sarama.RecordHeader{Key: []byte(headerContentType), Value: []byte(cloudEventsContentType)})
+		return value, headers, nil
+	}
+
+	attributes := map[string]string{
+		"specversion":          ce.SpecVersion,
+		"id":                   ce.ID,
+		"source":               ce.Source,
+		"type":                 ce.Type,
+		"subject":              ce.Subject,
+		"time":                 ce.Time,
+		ceExtensionVersion:     ce.EventVersion,
+		ceExtensionProducer:    ce.Producer,
+		ceExtensionTraceParent: ce.TraceParent,
+		ceExtensionTraceState:  ce.TraceState,
+	}
+	for name, value := range attributes {
+		if value != "" {
+			headers = append(headers, sarama.RecordHeader{Key: []byte(cloudEventsHeaderPrefix + name), Value: []byte(value)})
+		}
+	}
+	headers = append(headers, sarama.RecordHeader{Key: []byte(headerContentType), Value: []byte(jsonContentType)})
+	return data, headers, nil
+}
+
+// decodeCloudEvent detects a CloudEvent in either content mode.
+// It returns false for messages that are not CloudEvents.
+func decodeCloudEvent(message *sarama.ConsumerMessage) (*cloudEvent, bool, error) {
+	contentType := ""
+	binary := map[string]string{}
+	for _, h := range message.Headers {
+		key := strings.ToLower(string(h.Key))
+		switch {
+		case key == headerContentType:
+			contentType = string(h.Value)
+		case strings.HasPrefix(key, cloudEventsHeaderPrefix):
+			binary[strings.TrimPrefix(key, cloudEventsHeaderPrefix)] = string(h.Value)
+		}
+	}
+
+	if strings.HasPrefix(contentType, cloudEventsContentType) {
+		var ce cloudEvent
+		if err := json.Unmarshal(message.Value, &ce); err != nil {
+			return nil, true, fmt.Errorf("invalid structured cloud event: %w", err)
+		}
+		return &ce, true, nil
+	}
+
+	if binary["specversion"] == "" {
+		return nil, false, nil
+	}
+	return &cloudEvent{
+		SpecVersion:     binary["specversion"],
+		ID:              binary["id"],
+		Source:          binary["source"],
+		Type:            binary["type"],
+		Subject:         binary["subject"],
+		Time:            binary["time"],
+		DataContentType: contentType,
+		EventVersion:    binary[ceExtensionVersion],
+		Producer:        binary[ceExtensionProducer],
+		TraceParent:     binary[ceExtensionTraceParent],
+		TraceState:      binary[ceExtensionTraceState],
+		Data:            message.Value,
+	}, true, nil
+}
+
+// toEvent maps CloudEvent attributes back onto the Event envelope
+func (ce *cloudEvent) toEvent() Event[json.RawMessage] {
+	event := Event[json.RawMessage]{
+		ID:          ce.ID,
+		Type:        ce.Type,
+		Version:     1,
+		AggregateID: ce.Subject,
+		Producer:    ce.Producer,
+		TraceParent: ce.TraceParent,
+		TraceState:  ce.TraceState,
+		Data:        ce.Data,
+	}
+	if event.Producer == "" {
+		event.Producer = ce.Source
+	}
+	if v, err := strconv.Atoi(ce.EventVersion); err == nil {
+		event.Version = v
+	}
+	if t, err := time.Parse(time.RFC3339Nano, ce.Time); err == nil {
+		event.OccurredAt = t
+	}
+	return event
+}
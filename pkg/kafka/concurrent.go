@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// keyWorkerBuffer is the number of in-flight messages allowed per key worker
+const keyWorkerBuffer = 64
+
+// consumeByKey processes a partition with n workers. Messages with the same key go to the
+// same worker, so per-key ordering is preserved while different keys run concurrently.
+// Offsets are marked only up to the lowest contiguous completed offset, so a rebalance or
+// crash never commits past a message that is still being processed.
+func (h *consumerGroupHandler) consumeByKey(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, n int) error {
+	maxInFlight := n * keyWorkerBuffer
+
+	// Both channels hold maxInFlight messages so neither the dispatcher nor the workers block
+	work := make([]chan *sarama.ConsumerMessage, n)
+	done := make(chan *sarama.ConsumerMessage, maxInFlight)
+
+	var wg sync.WaitGroup
+	for i := range work {
+		work[i] = make(chan *sarama.ConsumerMessage, maxInFlight)
+		wg.Add(1)
+		go func(messages <-chan *sarama.ConsumerMessage) {
+			defer wg.Done()
+			h.runKeyWorker(session, messages, done)
+		}(work[i])
+	}
+
+	tracker := newOffsetTracker()
+	// stop waits for the messages being processed. Once the session has ended, queued messages
+	// that have not started are dropped unmarked and redelivered to the next owner.
+	stop := func() {
+		for _, ch := range work {
+			close(ch)
+		}
+		wg.Wait()
+		close(done)
+		for message := range done {
+			tracker.complete(session, message)
+		}
+	}
+
+	for {
+		// Stop reading new messages while the in-flight window is full
+		messages := claim.Messages()
+		if tracker.inFlight() >= maxInFlight {
+			messages = nil
+		}
+
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				logger.Log.Info("Message channel closed",
+					logger.Field{Key: "topic", Value: claim.Topic()},
+					logger.Field{Key: "partition", Value: claim.Partition()})
+				stop()
+				return nil
+			}
+			h.recordLag(claim, message)
+			if !h.waitWhilePaused(session, message.Topic, message.Partition) {
+				stop()
+				return nil
+			}
+			tracker.start(message)
+			work[keyWorker(message, n)] <- message
+
+		case message := <-done:
+			tracker.complete(session, message)
+
+		case <-session.Context().Done():
+			logger.Log.Info("Session context cancelled, exiting consume loop",
+				logger.Field{Key: "topic", Value: claim.Topic()},
+				logger.Field{Key: "partition", Value: claim.Partition()})
+			stop()
+			return nil
+		}
+	}
+}
+
+// runKeyWorker processes the messages queued for one worker until the channel is closed or the
+// session ends. A message is never started after the session has ended: its offset can no
+// longer be marked, so processing it would only duplicate it.
+func (h *consumerGroupHandler) runKeyWorker(session sarama.ConsumerGroupSession, messages <-chan *sarama.ConsumerMessage, done chan<- *sarama.ConsumerMessage) {
+	ctx := session.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok || ctx.Err() != nil {
+				return
+			}
+			h.process(ctx, message)
+			done <- message
+		}
+	}
+}
+
+// keyWorker picks the worker for a message. Keyless messages have no ordering to keep
+// and are spread by offset.
+func keyWorker(message *sarama.ConsumerMessage, n int) int {
+	if len(message.Key) == 0 {
+		return int(message.Offset % int64(n))
+	}
+	hash := fnv.New32a()
+	hash.Write(message.Key)
+	return int(hash.Sum32() % uint32(n))
+}
+
+// offsetTracker marks offsets in order as out-of-order completions fill the gaps.
+// It is only used from the partition's dispatch goroutine.
+type offsetTracker struct {
+	pending   []*sarama.ConsumerMessage // In dispatch (offset) order
+	completed map[int64]bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{completed: make(map[int64]bool)}
+}
+
+func (t *offsetTracker) start(message *sarama.ConsumerMessage) {
+	t.pending = append(t.pending, message)
+}
+
+func (t *offsetTracker) inFlight() int {
+	return len(t.pending)
+}
+
+func (t *offsetTracker) complete(session sarama.ConsumerGroupSession, message *sarama.ConsumerMessage) {
+	t.completed[message.Offset] = true
+
+	// Mark the longest completed prefix
+	marked := 0
+	for _, head := range t.pending {
+		if !t.completed[head.Offset] {
+			break
+		}
+		delete(t.completed, head.Offset)
+		marked++
+	}
+	if marked == 0 {
+		return
+	}
+	session.MarkMessage(t.pending[marked-1], "")
+	t.pending = t.pending[marked:]
+}
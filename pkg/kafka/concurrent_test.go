@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// fakeSession records marked offsets; other ConsumerGroupSession methods are not used
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *fakeSession) MarkMessage(message *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, message.Offset)
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func TestOffsetTracker(t *testing.T) {
+	tests := []struct {
+		name         string
+		started      []int64
+		completed    []int64
+		wantMarked   []int64
+		wantInFlight int
+	}{
+		{name: "in order", started: []int64{1, 2, 3}, completed: []int64{1, 2, 3}, wantMarked: []int64{1, 2, 3}},
+		{name: "gap holds later offsets", started: []int64{1, 2, 3}, completed: []int64{2, 3}, wantInFlight: 3},
+		{name: "filling the gap marks the prefix once", started: []int64{1, 2, 3}, completed: []int64{3, 2, 1}, wantMarked: []int64{3}},
+		{name: "partial prefix", started: []int64{1, 2, 3, 4}, completed: []int64{2, 1, 4}, wantMarked: []int64{2}, wantInFlight: 2},
+		{name: "offsets with holes from compaction", started: []int64{10, 15, 40}, completed: []int64{15, 10, 40}, wantMarked: []int64{15, 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &fakeSession{ctx: context.Background()}
+			tracker := newOffsetTracker()
+			messages := make(map[int64]*sarama.ConsumerMessage)
+			for _, offset := range tt.started {
+				messages[offset] = &sarama.ConsumerMessage{Offset: offset}
+				tracker.start(messages[offset])
+			}
+			for _, offset := range tt.completed {
+				tracker.complete(session, messages[offset])
+			}
+
+			if !slices.Equal(session.marked, tt.wantMarked) {
+				t.Errorf("marked = %v, want %v", session.marked, tt.wantMarked)
+			}
+			if got := tracker.inFlight(); got != tt.wantInFlight {
+				t.Errorf("inFlight() = %d, want %d", got, tt.wantInFlight)
+			}
+		})
+	}
+}
+
+func TestRunKeyWorker(t *testing.T) {
+	tests := []struct {
+		name          string
+		sessionEnded  bool
+		wantProcessed int
+	}{
+		{name: "drains queued messages while the session is active", wantProcessed: 3},
+		{name: "drops queued messages once the session has ended", sessionEnded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.sessionEnded {
+				cancel()
+			}
+
+			processed := 0
+			h := &consumerGroupHandler{handler: func(ctx context.Context, message *sarama.ConsumerMessage) error {
+				processed++
+				return nil
+			}}
+
+			messages := make(chan *sarama.ConsumerMessage, 3)
+			done := make(chan *sarama.ConsumerMessage, 3)
+			for offset := int64(0); offset < 3; offset++ {
+				messages <- &sarama.ConsumerMessage{Topic: "orders", Offset: offset}
+			}
+			close(messages)
+
+			h.runKeyWorker(&fakeSession{ctx: ctx}, messages, done)
+
+			if processed != tt.wantProcessed {
+				t.Errorf("processed %d messages, want %d", processed, tt.wantProcessed)
+			}
+			if len(done) != tt.wantProcessed {
+				t.Errorf("completed %d messages, want %d", len(done), tt.wantProcessed)
+			}
+		})
+	}
+}
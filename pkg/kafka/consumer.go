@@ -8,23 +8,43 @@ import (
 
 	appConfig "go1/config"
 	"go1/pkg/logger"
+	"go1/pkg/metrics"
 
 	"github.com/IBM/sarama"
 )
 
 type Consumer struct {
-	consumerGroup sarama.ConsumerGroup
+	consumerGroup sarama.ConsumerGroup // nil while stopped; guarded by control.mu
+	newGroup      func() (sarama.ConsumerGroup, error)
 	opts          ConsumerOptions
 	producer      *KafkaProducer
 	topics        []string
+	control       consumerControl
 }
 
 type MessageHandler func(context.Context, *sarama.ConsumerMessage) error
 
+// Retry headers
+const (
+	HeaderAttempt   = "x-attempt"
+	HeaderNotBefore = "x-not-before" // Unix milliseconds before which a retried message must not be processed
+
+	// Where the message was first consumed, kept across retry and DLQ republishing
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+
+	// Why the message was dead-lettered
+	HeaderDLQReason = "x-dlq-reason"
+	HeaderDLQStack  = "x-dlq-stack"
+)
+
 // TopicRetryConfig defines retry behavior for a specific topic.
 type TopicRetryConfig struct {
 	MaxAttempts int
-	Backoff     time.Duration
+	// Backoff delays redelivery from the retry topic. Failed messages are republished
+	// immediately with a not-before header; only the retry partition waits for it.
+	Backoff time.Duration
 }
 
 // ConsumerOptions defines retry/DLQ behavior for the consumer.
@@ -37,16 +57,27 @@ type ConsumerOptions struct {
 	DLQTopicSuffix string
 	// TopicRetryConfig maps topic names to their retry configs. If a topic is not in the map, retry is disabled.
 	TopicRetryConfig map[string]TopicRetryConfig
+	// KeyConcurrency > 1 processes each partition with that many workers, hashed by message key.
+	// Ordering is kept per key only. Retry topics are always processed sequentially.
+	KeyConcurrency int
+	// BatchTopics maps topics to batch handlers; their partitions are consumed in batches.
+	BatchTopics map[string]BatchTopic
 }
 
-func NewConsumerWithOptions(brokers string, topics []string, options ConsumerOptions, producerConfig appConfig.KafkaProducerConfig, consumerConfig appConfig.KafkaConsumerConfig) (*Consumer, error) {
-	config := sarama.NewConfig()
+// NewConsumerWithOptions creates a consumer group member and the producer it uses for retry/DLQ
+// publishing; both connect with the brokers and TLS/SASL settings of kafkaConfig
+func NewConsumerWithOptions(kafkaConfig appConfig.KafkaConfig, topics []string, options ConsumerOptions) (*Consumer, error) {
+	consumerConfig := kafkaConfig.Consumer
+	config, err := newSaramaConfig(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
 	config.Consumer.Return.Errors = true
-	config.Consumer.Offsets.Initial = sarama.OffsetNewest
-	config.Version = sarama.V2_6_0_0
-	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
 
 	// Apply consumer config
+	if err := applyConsumerConfig(config, kafkaConfig); err != nil {
+		return nil, err
+	}
 	if consumerConfig.SessionTimeoutMs > 0 {
 		config.Consumer.Group.Session.Timeout = time.Duration(consumerConfig.SessionTimeoutMs) * time.Millisecond
 	}
@@ -57,14 +88,25 @@ func NewConsumerWithOptions(brokers string, topics []string, options ConsumerOpt
 		config.Consumer.MaxProcessingTime = time.Duration(consumerConfig.MaxProcessingTimeMs) * time.Millisecond
 	}
 
-	brokerList := strings.Split(brokers, ",")
-	consumerGroup, err := sarama.NewConsumerGroup(brokerList, options.GroupID, config)
+	brokerList := strings.Split(kafkaConfig.Brokers, ",")
+	newGroup := func() (sarama.ConsumerGroup, error) {
+		return sarama.NewConsumerGroup(brokerList, options.GroupID, config)
+	}
+	consumerGroup, err := newGroup()
 	if err != nil {
 		return nil, err
 	}
 
-	prod, err := NewProducer(brokers, producerConfig)
+	// With a transactional id, a retry/DLQ republish and its source offset commit are atomic.
+	// Successfully handled messages are still marked and committed outside transactions.
+	var prod *KafkaProducer
+	if prefix := kafkaConfig.Producer.TransactionalID; prefix != "" {
+		prod, err = NewTransactionalProducer(kafkaConfig, consumerTransactionalID(prefix, options.GroupID))
+	} else {
+		prod, err = NewProducer(kafkaConfig)
+	}
 	if err != nil {
+		consumerGroup.Close()
 		return nil, err
 	}
 
@@ -81,9 +123,11 @@ func NewConsumerWithOptions(brokers string, topics []string, options ConsumerOpt
 
 	return &Consumer{
 		consumerGroup: consumerGroup,
+		newGroup:      newGroup,
 		topics:        topics,
 		opts:          options,
 		producer:      prod,
+		control:       newConsumerControl(),
 	}, nil
 }
 
@@ -95,18 +139,25 @@ func (c *Consumer) Consume(ctx context.Context, handler MessageHandler) error {
 	}
 
 	// Start error handling goroutine
-	go func() {
-		for err := range c.consumerGroup.Errors() {
-			logger.Log.Error("Consumer group error", logger.Field{Key: "error", Value: err})
-		}
-	}()
+	go logGroupErrors(c.consumerGroup)
 
-	// Consume runs in a loop to handle rebalancing
+	// Consume runs in a loop to handle rebalancing and stop/start
 	for {
+		if err := c.waitUntilStarted(ctx); err != nil {
+			return err
+		}
+		group, sessionCtx, cancelSession, err := c.joinGroup(ctx)
+		if err != nil {
+			logger.Log.Error("Failed to join consumer group", logger.Field{Key: "error", Value: err})
+			return err
+		}
+
 		// `Consume` should be called inside an infinite loop, when a
 		// server-side rebalance happens, the consumer session will need to be
 		// recreated to get the new claims
-		if err := c.consumerGroup.Consume(ctx, c.topics, consumerHandler); err != nil {
+		err = group.Consume(sessionCtx, c.topics, consumerHandler)
+		cancelSession()
+		if err != nil {
 			logger.Log.Error("Consumer group error", logger.Field{Key: "error", Value: err})
 			return err
 		}
@@ -115,15 +166,19 @@ func (c *Consumer) Consume(ctx context.Context, handler MessageHandler) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		c.leaveGroupIfStopped()
 
 		// Reset ready channel for next session
 		consumerHandler.ready = make(chan bool)
 	}
 }
 
+// handleError retries failed messages through the retry topic and dead-letters them once
+// attempts are exhausted. Permanent errors go to the DLQ at once, even without retry config.
 func (c *Consumer) handleError(ctx context.Context, message *sarama.ConsumerMessage, err error) {
 	// Get retry config for this topic
 	retryConfig, hasRetryConfig := c.opts.TopicRetryConfig[message.Topic]
+	permanent := IsPermanent(err)
 
 	// Check if DLQ topic (never retry DLQ)
 	if strings.HasSuffix(message.Topic, c.opts.DLQTopicSuffix) {
@@ -134,7 +189,7 @@ func (c *Consumer) handleError(ctx context.Context, message *sarama.ConsumerMess
 	}
 
 	// If no retry config for this topic, just log
-	if !hasRetryConfig {
+	if !hasRetryConfig && !permanent {
 		logger.Log.Warn("No retry config for topic",
 			logger.Field{Key: "topic", Value: message.Topic},
 			logger.Field{Key: "error", Value: err})
@@ -148,42 +203,46 @@ func (c *Consumer) handleError(ctx context.Context, message *sarama.ConsumerMess
 		logger.Field{Key: "topic", Value: message.Topic},
 		logger.Field{Key: "attempt", Value: attempts},
 		logger.Field{Key: "maxAttempts", Value: retryConfig.MaxAttempts},
+		logger.Field{Key: "permanent", Value: permanent},
 		logger.Field{Key: "error", Value: err})
 
 	if c.producer == nil {
 		return
 	}
 
-	// Update headers with new attempt count
-	updatedHeaders := updateAttemptsHeader(message.Headers, attempts)
+	// Update headers with new attempt count and where the message came from
+	updatedHeaders := withOriginHeaders(updateAttemptsHeader(message.Headers, attempts), message)
 
-	// If max attempts reached, send to DLQ
-	if attempts >= retryConfig.MaxAttempts {
+	// If the error is permanent or max attempts reached, send to DLQ
+	if permanent || attempts >= retryConfig.MaxAttempts {
+		updatedHeaders = removeHeader(updatedHeaders, HeaderNotBefore)
+		updatedHeaders = append(updatedHeaders, sarama.RecordHeader{Key: []byte(HeaderDLQReason), Value: []byte(err.Error())})
+		if stack := errorStack(err); stack != "" {
+			updatedHeaders = append(updatedHeaders, sarama.RecordHeader{Key: []byte(HeaderDLQStack), Value: []byte(stack)})
+		}
 		c.sendToDLQ(ctx, message, updatedHeaders, attempts)
 		return
 	}
 
-	// Apply backoff before retry
-	if retryConfig.Backoff > 0 {
-		select {
-		case <-time.After(retryConfig.Backoff):
-		case <-ctx.Done():
-			return
-		}
-	}
-
-	// Send to retry topic
+	// Send to retry topic right away; the retry consumer holds it until the backoff elapses
+	notBefore := time.Now().Add(retryConfig.Backoff).UnixMilli()
+	updatedHeaders = append(removeHeader(updatedHeaders, HeaderNotBefore), sarama.RecordHeader{
+		Key:   []byte(HeaderNotBefore),
+		Value: []byte(strconv.FormatInt(notBefore, 10)),
+	})
 	c.sendToRetry(ctx, message, updatedHeaders, attempts)
 }
 
 func (c *Consumer) sendToDLQ(ctx context.Context, message *sarama.ConsumerMessage, headers []sarama.RecordHeader, attempts int) {
-	dlqTopic := getBaseTopic(message.Topic, c.opts.RetryTopicSuffix) + c.opts.DLQTopicSuffix
+	baseTopic := getBaseTopic(message.Topic, c.opts.RetryTopicSuffix)
+	dlqTopic := baseTopic + c.opts.DLQTopicSuffix
 
-	if err := c.producer.PublishWithHeaders(ctx, dlqTopic, message.Key, message.Value, headers); err != nil {
+	if err := c.republish(ctx, message, dlqTopic, headers); err != nil {
 		logger.Log.Error("Failed to publish to DLQ",
 			logger.Field{Key: "dlqTopic", Value: dlqTopic},
 			logger.Field{Key: "error", Value: err})
 	} else {
+		metrics.KafkaConsumerDLQTotal.WithLabelValues(baseTopic).Inc()
 		logger.Log.Info("Message sent to DLQ",
 			logger.Field{Key: "dlqTopic", Value: dlqTopic},
 			logger.Field{Key: "attempts", Value: attempts})
@@ -191,21 +250,57 @@ func (c *Consumer) sendToDLQ(ctx context.Context, message *sarama.ConsumerMessag
 }
 
 func (c *Consumer) sendToRetry(ctx context.Context, message *sarama.ConsumerMessage, headers []sarama.RecordHeader, attempts int) {
-	retryTopic := getBaseTopic(message.Topic, c.opts.RetryTopicSuffix) + c.opts.RetryTopicSuffix
-	if err := c.producer.PublishWithHeaders(ctx, retryTopic, message.Key, message.Value, headers); err != nil {
+	baseTopic := getBaseTopic(message.Topic, c.opts.RetryTopicSuffix)
+	retryTopic := baseTopic + c.opts.RetryTopicSuffix
+	if err := c.republish(ctx, message, retryTopic, headers); err != nil {
 		logger.Log.Error("Failed to publish to retry topic",
 			logger.Field{Key: "retryTopic", Value: retryTopic},
 			logger.Field{Key: "error", Value: err})
 	} else {
+		metrics.KafkaConsumerRetriesTotal.WithLabelValues(baseTopic).Inc()
 		logger.Log.Info("Message sent to retry topic",
 			logger.Field{Key: "retryTopic", Value: retryTopic},
 			logger.Field{Key: "attempts", Value: attempts})
 	}
 }
 
+// republish sends a failed message on to its retry or DLQ topic. A transactional producer
+// commits the source offset in the same transaction where offsets are committed in order,
+// so a crash cannot republish it twice. This is the only transactional step: handler side
+// effects and the offsets of successful messages are at-least-once.
+func (c *Consumer) republish(ctx context.Context, message *sarama.ConsumerMessage, topic string, headers []sarama.RecordHeader) error {
+	if !c.producer.IsTransactional() {
+		return c.producer.PublishWithHeaders(ctx, topic, message.Key, message.Value, headers)
+	}
+	return c.producer.Transact(func(tx *Transaction) error {
+		if err := tx.Publish(ctx, topic, message.Key, message.Value, headers); err != nil {
+			return err
+		}
+		if !c.commitsInOrder(message.Topic) {
+			return nil
+		}
+		return tx.AddOffsets(c.opts.GroupID, message)
+	})
+}
+
+// commitsInOrder reports whether committing a message's offset implies all earlier messages
+// of its partition are done. Per-key workers complete out of order, so their offsets are
+// only committed by the offset tracker.
+func (c *Consumer) commitsInOrder(topic string) bool {
+	if _, ok := c.opts.BatchTopics[topic]; ok {
+		return true
+	}
+	return c.opts.KeyConcurrency <= 1 || strings.HasSuffix(topic, c.opts.RetryTopicSuffix)
+}
+
 func (c *Consumer) Close() {
-	if c.consumerGroup != nil {
-		c.consumerGroup.Close()
+	c.control.mu.Lock()
+	group := c.consumerGroup
+	c.consumerGroup = nil
+	c.control.mu.Unlock()
+
+	if group != nil {
+		group.Close()
 	}
 	if c.producer != nil {
 		c.producer.Close()
@@ -222,7 +317,7 @@ func getBaseTopic(topic, retrySuffix string) string {
 
 func getAttempts(hdrs []*sarama.RecordHeader) int {
 	for _, h := range hdrs {
-		if strings.EqualFold(string(h.Key), "x-attempt") {
+		if strings.EqualFold(string(h.Key), HeaderAttempt) {
 			if v, err := strconv.Atoi(string(h.Value)); err == nil {
 				return v
 			}
@@ -236,18 +331,55 @@ func updateAttemptsHeader(hdrs []*sarama.RecordHeader, attempts int) []sarama.Re
 	updated := make([]sarama.RecordHeader, 0, len(hdrs)+1)
 	// Copy all headers except x-attempt
 	for _, h := range hdrs {
-		if !strings.EqualFold(string(h.Key), "x-attempt") {
+		if !strings.EqualFold(string(h.Key), HeaderAttempt) {
 			updated = append(updated, *h)
 		}
 	}
 	// Add updated x-attempt header
 	updated = append(updated, sarama.RecordHeader{
-		Key:   []byte("x-attempt"),
+		Key:   []byte(HeaderAttempt),
 		Value: []byte(strconv.Itoa(attempts)),
 	})
 	return updated
 }
 
+// withOriginHeaders records the topic, partition and offset the message was first consumed from
+func withOriginHeaders(hdrs []sarama.RecordHeader, message *sarama.ConsumerMessage) []sarama.RecordHeader {
+	for _, h := range hdrs {
+		if strings.EqualFold(string(h.Key), HeaderOriginalTopic) {
+			return hdrs
+		}
+	}
+	return append(hdrs,
+		sarama.RecordHeader{Key: []byte(HeaderOriginalTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+	)
+}
+
+func removeHeader(hdrs []sarama.RecordHeader, key string) []sarama.RecordHeader {
+	kept := hdrs[:0]
+	for _, h := range hdrs {
+		if !strings.EqualFold(string(h.Key), key) {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
+
+// getNotBefore returns the due time of a retried message, or the zero time if it has none
+func getNotBefore(hdrs []*sarama.RecordHeader) time.Time {
+	for _, h := range hdrs {
+		if strings.EqualFold(string(h.Key), HeaderNotBefore) {
+			if ms, err := strconv.ParseInt(string(h.Value), 10, 64); err == nil {
+				return time.UnixMilli(ms)
+			}
+			break
+		}
+	}
+	return time.Time{}
+}
+
 // consumerGroupHandler implements sarama.ConsumerGroupHandler
 type consumerGroupHandler struct {
 	consumer *Consumer
@@ -256,15 +388,19 @@ type consumerGroupHandler struct {
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim
-func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
 	// Close ready channel to signal that consumer is ready
 	close(h.ready)
+	h.consumer.setAssignment(session.Claims())
+	metrics.KafkaConsumerRebalancesTotal.WithLabelValues(h.consumer.opts.GroupID).Inc()
 	logger.Log.Info("Consumer group session setup completed")
 	return nil
 }
 
 // Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited
-func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+func (h *consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	h.forgetLag(session.Claims())
+	h.consumer.setAssignment(nil)
 	logger.Log.Info("Consumer group session cleanup completed")
 	return nil
 }
@@ -273,13 +409,24 @@ func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
 // NOTE: Do not move the code below to a goroutine.
 // The `ConsumeClaim` itself is called within a goroutine by sarama for each partition, see:
 // https://github.com/IBM/sarama/blob/main/consumer_group.go#L27-L29
-// This ensures each partition processes messages sequentially to maintain ordering.
+// This ensures each partition processes messages sequentially to maintain ordering,
+// unless KeyConcurrency fans them out to per-key workers.
 func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	logger.Log.Info("Starting to consume partition",
 		logger.Field{Key: "topic", Value: claim.Topic()},
 		logger.Field{Key: "partition", Value: claim.Partition()},
 		logger.Field{Key: "initialOffset", Value: claim.InitialOffset()})
 
+	// Keep partitions paused across rebalances
+	h.consumer.applyPause(claim.Topic(), claim.Partition())
+
+	if batchTopic, ok := h.consumer.opts.BatchTopics[claim.Topic()]; ok {
+		return h.consumeBatches(session, claim, batchTopic)
+	}
+	if n := h.consumer.opts.KeyConcurrency; n > 1 && !strings.HasSuffix(claim.Topic(), h.consumer.opts.RetryTopicSuffix) {
+		return h.consumeByKey(session, claim, n)
+	}
+
 	for {
 		select {
 		case message, ok := <-claim.Messages():
@@ -289,12 +436,19 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 					logger.Field{Key: "partition", Value: claim.Partition()})
 				return nil
 			}
+			h.recordLag(claim, message)
+			if !h.waitWhilePaused(session, message.Topic, message.Partition) {
+				return nil
+			}
 
-			// Process message sequentially to maintain ordering within partition
-			if err := h.handler(session.Context(), message); err != nil {
-				h.consumer.handleError(session.Context(), message, err)
+			// Retried messages wait for their backoff without blocking other partitions
+			if strings.HasSuffix(message.Topic, h.consumer.opts.RetryTopicSuffix) && !h.waitUntilDue(session, message) {
+				return nil
 			}
 
+			// Process message sequentially to maintain ordering within partition
+			h.process(session.Context(), message)
+
 			// Mark message as processed (commit offset)
 			session.MarkMessage(message, "")
 
@@ -306,3 +460,45 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 		}
 	}
 }
+
+// waitUntilDue holds a retry message until its not-before time. Fetching for the partition is
+// paused meanwhile so sarama does not buffer it; other partitions keep consuming. Retry messages
+// share the topic's backoff, so the head of the partition is always the next one due.
+// Returns false if the session ended first; the message is then redelivered unmarked.
+func (h *consumerGroupHandler) waitUntilDue(session sarama.ConsumerGroupSession, message *sarama.ConsumerMessage) bool {
+	delay := time.Until(getNotBefore(message.Headers))
+	if delay <= 0 {
+		return true
+	}
+
+	h.consumer.pauseFetching(message.Topic, message.Partition)
+	defer h.consumer.resumeUnlessPaused(message.Topic, message.Partition)
+
+	logger.Log.Debug("Delaying retry message",
+		logger.Field{Key: "topic", Value: message.Topic},
+		logger.Field{Key: "partition", Value: message.Partition},
+		logger.Field{Key: "offset", Value: message.Offset},
+		logger.Field{Key: "delay", Value: delay.String()})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-session.Context().Done():
+		return false
+	}
+}
+
+// process runs the handler inside a consumer span continuing the producer's trace. Failures are
+// retried or dead-lettered under the same span, so republished messages stay in the trace.
+func (h *consumerGroupHandler) process(ctx context.Context, message *sarama.ConsumerMessage) {
+	ctx, span := startConsumerSpan(ctx, message)
+	start := time.Now()
+	err := h.handler(ctx, message)
+	observeHandled(message.Topic, start, err)
+	if err != nil {
+		h.consumer.handleError(ctx, message, err)
+	}
+	endSpan(span, err)
+}
@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	appConfig "go1/config"
+
+	"github.com/IBM/sarama"
+)
+
+// defaultVersion is the protocol version used when kafka.consumer.version is not set
+var defaultVersion = sarama.V2_6_0_0
+
+// applyConsumerConfig applies the start offset, rebalancing, protocol version, fetch sizes,
+// isolation level and static membership of kafka.consumer
+func applyConsumerConfig(config *sarama.Config, kafkaConfig appConfig.KafkaConfig) error {
+	consumerConfig := kafkaConfig.Consumer
+
+	version, err := kafkaVersion(consumerConfig)
+	if err != nil {
+		return err
+	}
+	config.Version = version
+
+	// Only used by groups without committed offsets, e.g. a new group backfilling history
+	switch consumerConfig.InitialOffset {
+	case "", "newest":
+		config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	case "oldest":
+		config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	default:
+		return fmt.Errorf("unknown kafka.consumer.initialOffset %q", consumerConfig.InitialOffset)
+	}
+
+	switch consumerConfig.RebalanceStrategy {
+	case "", "roundrobin":
+		config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
+	case "range":
+		config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRange()}
+	case "sticky":
+		config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+	case "cooperative-sticky":
+		// sarama only implements the eager rebalance protocol
+		return fmt.Errorf("kafka.consumer.rebalanceStrategy cooperative-sticky is not supported by the client; use sticky")
+	default:
+		return fmt.Errorf("unknown kafka.consumer.rebalanceStrategy %q", consumerConfig.RebalanceStrategy)
+	}
+
+	// Configure fetching
+	if consumerConfig.FetchMinBytes > 0 {
+		config.Consumer.Fetch.Min = int32(consumerConfig.FetchMinBytes)
+	}
+	if consumerConfig.FetchDefaultBytes > 0 {
+		config.Consumer.Fetch.Default = int32(consumerConfig.FetchDefaultBytes)
+	}
+	if consumerConfig.FetchMaxBytes > 0 {
+		config.Consumer.Fetch.Max = int32(consumerConfig.FetchMaxBytes)
+	}
+	if consumerConfig.FetchMaxWaitMs > 0 {
+		config.Consumer.MaxWaitTime = time.Duration(consumerConfig.FetchMaxWaitMs) * time.Millisecond
+	}
+
+	switch consumerConfig.IsolationLevel {
+	case "":
+		if kafkaConfig.Producer.TransactionalID != "" {
+			// Skip messages of aborted retry/DLQ transactions
+			config.Consumer.IsolationLevel = sarama.ReadCommitted
+		}
+	case "read_uncommitted":
+		config.Consumer.IsolationLevel = sarama.ReadUncommitted
+	case "read_committed":
+		config.Consumer.IsolationLevel = sarama.ReadCommitted
+	default:
+		return fmt.Errorf("unknown kafka.consumer.isolationLevel %q", consumerConfig.IsolationLevel)
+	}
+
+	// Static members keep their assignment across restarts within the session timeout
+	config.Consumer.Group.InstanceId = consumerConfig.InstanceID
+	return nil
+}
+
+// kafkaVersion parses kafka.consumer.version, which the group admin and provisioner speak too
+func kafkaVersion(consumerConfig appConfig.KafkaConsumerConfig) (sarama.KafkaVersion, error) {
+	if consumerConfig.Version == "" {
+		return defaultVersion, nil
+	}
+	version, err := sarama.ParseKafkaVersion(consumerConfig.Version)
+	if err != nil {
+		return sarama.KafkaVersion{}, fmt.Errorf("invalid kafka.consumer.version: %w", err)
+	}
+	return version, nil
+}
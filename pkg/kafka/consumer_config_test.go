@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	appConfig "go1/config"
+
+	"github.com/IBM/sarama"
+)
+
+func TestApplyConsumerConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		consumer appConfig.KafkaConsumerConfig
+		txnID    string
+		wantErr  bool
+		check    func(t *testing.T, config *sarama.Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, config *sarama.Config) {
+				if config.Version != defaultVersion {
+					t.Errorf("Version = %v, want %v", config.Version, defaultVersion)
+				}
+				if config.Consumer.Offsets.Initial != sarama.OffsetNewest {
+					t.Errorf("Offsets.Initial = %d, want newest", config.Consumer.Offsets.Initial)
+				}
+				if got := config.Consumer.Group.Rebalance.GroupStrategies[0].Name(); got != sarama.RoundRobinBalanceStrategyName {
+					t.Errorf("strategy = %s, want roundrobin", got)
+				}
+				if config.Consumer.IsolationLevel != sarama.ReadUncommitted {
+					t.Errorf("IsolationLevel = %d, want read_uncommitted", config.Consumer.IsolationLevel)
+				}
+			},
+		},
+		{
+			name: "all settings",
+			consumer: appConfig.KafkaConsumerConfig{
+				InitialOffset:     "oldest",
+				RebalanceStrategy: "sticky",
+				Version:           "3.6.0",
+				FetchMinBytes:     1024,
+				FetchDefaultBytes: 2 << 20,
+				FetchMaxBytes:     8 << 20,
+				FetchMaxWaitMs:    250,
+				IsolationLevel:    "read_committed",
+				InstanceID:        "worker-1",
+			},
+			check: func(t *testing.T, config *sarama.Config) {
+				if config.Version != sarama.V3_6_0_0 {
+					t.Errorf("Version = %v, want 3.6.0", config.Version)
+				}
+				if config.Consumer.Offsets.Initial != sarama.OffsetOldest {
+					t.Errorf("Offsets.Initial = %d, want oldest", config.Consumer.Offsets.Initial)
+				}
+				if got := config.Consumer.Group.Rebalance.GroupStrategies[0].Name(); got != sarama.StickyBalanceStrategyName {
+					t.Errorf("strategy = %s, want sticky", got)
+				}
+				if config.Consumer.Fetch.Min != 1024 || config.Consumer.Fetch.Default != 2<<20 || config.Consumer.Fetch.Max != 8<<20 {
+					t.Errorf("Fetch = %+v", config.Consumer.Fetch)
+				}
+				if config.Consumer.MaxWaitTime != 250*time.Millisecond {
+					t.Errorf("MaxWaitTime = %v, want 250ms", config.Consumer.MaxWaitTime)
+				}
+				if config.Consumer.IsolationLevel != sarama.ReadCommitted {
+					t.Errorf("IsolationLevel = %d, want read_committed", config.Consumer.IsolationLevel)
+				}
+				if config.Consumer.Group.InstanceId != "worker-1" {
+					t.Errorf("InstanceId = %q, want worker-1", config.Consumer.Group.InstanceId)
+				}
+			},
+		},
+		{
+			name:  "transactional id defaults to read_committed",
+			txnID: "go1-worker",
+			check: func(t *testing.T, config *sarama.Config) {
+				if config.Consumer.IsolationLevel != sarama.ReadCommitted {
+					t.Errorf("IsolationLevel = %d, want read_committed", config.Consumer.IsolationLevel)
+				}
+			},
+		},
+		{
+			name:     "explicit isolation level wins over transactional id",
+			consumer: appConfig.KafkaConsumerConfig{IsolationLevel: "read_uncommitted"},
+			txnID:    "go1-worker",
+			check: func(t *testing.T, config *sarama.Config) {
+				if config.Consumer.IsolationLevel != sarama.ReadUncommitted {
+					t.Errorf("IsolationLevel = %d, want read_uncommitted", config.Consumer.IsolationLevel)
+				}
+			},
+		},
+		{name: "invalid version", consumer: appConfig.KafkaConsumerConfig{Version: "latest"}, wantErr: true},
+		{name: "unknown initial offset", consumer: appConfig.KafkaConsumerConfig{InitialOffset: "earliest"}, wantErr: true},
+		{name: "cooperative-sticky is rejected", consumer: appConfig.KafkaConsumerConfig{RebalanceStrategy: "cooperative-sticky"}, wantErr: true},
+		{name: "unknown rebalance strategy", consumer: appConfig.KafkaConsumerConfig{RebalanceStrategy: "random"}, wantErr: true},
+		{name: "unknown isolation level", consumer: appConfig.KafkaConsumerConfig{IsolationLevel: "serializable"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kafkaConfig := appConfig.KafkaConfig{Consumer: tt.consumer}
+			kafkaConfig.Producer.TransactionalID = tt.txnID
+
+			config := sarama.NewConfig()
+			err := applyConsumerConfig(config, kafkaConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyConsumerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				tt.check(t, config)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package kafka
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// allPartitions marks a topic paused as a whole, including partitions assigned later
+const allPartitions int32 = -1
+
+// consumerControl is the runtime state changed through the worker admin endpoints.
+// Pauses and stops outlive rebalances: they are re-applied to every new session.
+type consumerControl struct {
+	mu            sync.Mutex
+	assignment    map[string][]int32
+	paused        map[string]map[int32]bool
+	resumed       chan struct{} // Closed and replaced on every resume to wake paused partitions
+	stopped       bool
+	started       chan struct{} // Closed and replaced on every start
+	cancelSession context.CancelFunc
+}
+
+func newConsumerControl() consumerControl {
+	return consumerControl{
+		paused:  make(map[string]map[int32]bool),
+		resumed: make(chan struct{}),
+		started: make(chan struct{}),
+	}
+}
+
+// isPaused must be called with mu held
+func (c *consumerControl) isPaused(topic string, partition int32) bool {
+	partitions := c.paused[topic]
+	return partitions[allPartitions] || partitions[partition]
+}
+
+// ConsumerState describes the consumer's assignment and runtime controls
+type ConsumerState struct {
+	Stopped    bool               `json:"stopped"`
+	Assignment map[string][]int32 `json:"assignment"`
+	Paused     map[string][]int32 `json:"paused"` // -1 means the whole topic
+}
+
+// State returns this member's current assignment and what is paused
+func (c *Consumer) State() ConsumerState {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	state := ConsumerState{
+		Stopped:    c.control.stopped,
+		Assignment: make(map[string][]int32, len(c.control.assignment)),
+		Paused:     make(map[string][]int32, len(c.control.paused)),
+	}
+	for topic, partitions := range c.control.assignment {
+		state.Assignment[topic] = append([]int32(nil), partitions...)
+	}
+	for topic, partitions := range c.control.paused {
+		for partition := range partitions {
+			state.Paused[topic] = append(state.Paused[topic], partition)
+		}
+		sort.Slice(state.Paused[topic], func(i, j int) bool { return state.Paused[topic][i] < state.Paused[topic][j] })
+	}
+	return state
+}
+
+// Topics returns the topics the consumer subscribes to, including retry topics
+func (c *Consumer) Topics() []string {
+	return c.topics
+}
+
+// Pause stops fetching and processing the given partitions of topic, or all of its partitions
+// when none are given. Messages already being processed finish; the rest wait until Resume.
+func (c *Consumer) Pause(topic string, partitions []int32) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if len(partitions) == 0 {
+		partitions = []int32{allPartitions}
+	}
+	if c.control.paused[topic] == nil {
+		c.control.paused[topic] = make(map[int32]bool)
+	}
+	for _, partition := range partitions {
+		c.control.paused[topic][partition] = true
+	}
+
+	if c.consumerGroup != nil {
+		c.consumerGroup.Pause(map[string][]int32{topic: c.assignedOf(topic, partitions)})
+	}
+	logger.Log.Info("Consumer paused",
+		logger.Field{Key: "topic", Value: topic},
+		logger.Field{Key: "partitions", Value: partitions})
+}
+
+// Resume undoes Pause. Resuming the whole topic clears all of its pauses; resuming single
+// partitions of a topic paused as a whole leaves them paused.
+func (c *Consumer) Resume(topic string, partitions []int32) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if len(partitions) == 0 {
+		delete(c.control.paused, topic)
+	} else {
+		for _, partition := range partitions {
+			delete(c.control.paused[topic], partition)
+		}
+		if len(c.control.paused[topic]) == 0 {
+			delete(c.control.paused, topic)
+		}
+	}
+
+	var resumed []int32
+	for _, partition := range c.assignedOf(topic, partitions) {
+		if !c.control.isPaused(topic, partition) {
+			resumed = append(resumed, partition)
+		}
+	}
+	if c.consumerGroup != nil && len(resumed) > 0 {
+		c.consumerGroup.Resume(map[string][]int32{topic: resumed})
+	}
+	close(c.control.resumed)
+	c.control.resumed = make(chan struct{})
+
+	logger.Log.Info("Consumer resumed",
+		logger.Field{Key: "topic", Value: topic},
+		logger.Field{Key: "partitions", Value: resumed})
+}
+
+// assignedOf resolves partitions (or the whole topic) to the assigned partitions; mu must be held
+func (c *Consumer) assignedOf(topic string, partitions []int32) []int32 {
+	if len(partitions) == 0 || partitions[0] == allPartitions {
+		return c.control.assignment[topic]
+	}
+	return partitions
+}
+
+// Stop ends the current session and leaves the consumer group until Start. Unlike Pause,
+// the partitions are handed to other members, and once every member has stopped the group
+// is empty and its offsets can be reset. Static members (kafka.consumer.instanceId) do not
+// send a leave request, so the group only drops them after the session timeout.
+func (c *Consumer) Stop() {
+	c.control.mu.Lock()
+	if c.control.stopped {
+		c.control.mu.Unlock()
+		return
+	}
+	c.control.stopped = true
+	cancel := c.control.cancelSession
+	c.control.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	logger.Log.Info("Consumer stopping", logger.Field{Key: "groupId", Value: c.opts.GroupID})
+}
+
+// Start rejoins the consumer group after Stop
+func (c *Consumer) Start() {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if !c.control.stopped {
+		return
+	}
+	c.control.stopped = false
+	close(c.control.started)
+	c.control.started = make(chan struct{})
+	logger.Log.Info("Consumer starting", logger.Field{Key: "groupId", Value: c.opts.GroupID})
+}
+
+// waitUntilStarted blocks while the consumer is stopped
+func (c *Consumer) waitUntilStarted(ctx context.Context) error {
+	c.control.mu.Lock()
+	if !c.control.stopped {
+		c.control.mu.Unlock()
+		return nil
+	}
+	started := c.control.started
+	c.control.mu.Unlock()
+
+	select {
+	case <-started:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// joinGroup returns the consumer group, recreating it after a stop, and the context of the
+// next session, which Stop cancels
+func (c *Consumer) joinGroup(ctx context.Context) (sarama.ConsumerGroup, context.Context, context.CancelFunc, error) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+
+	if c.consumerGroup == nil {
+		group, err := c.newGroup()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c.consumerGroup = group
+		go logGroupErrors(group)
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	c.control.cancelSession = cancel
+	return c.consumerGroup, sessionCtx, cancel, nil
+}
+
+// leaveGroupIfStopped closes the consumer group after a stopped session, leaving the group
+func (c *Consumer) leaveGroupIfStopped() {
+	c.control.mu.Lock()
+	if !c.control.stopped || c.consumerGroup == nil {
+		c.control.mu.Unlock()
+		return
+	}
+	group := c.consumerGroup
+	c.consumerGroup = nil
+	c.control.mu.Unlock()
+
+	if err := group.Close(); err != nil {
+		logger.Log.Error("Failed to leave consumer group", logger.Field{Key: "error", Value: err})
+		return
+	}
+	logger.Log.Info("Consumer stopped, left group", logger.Field{Key: "groupId", Value: c.opts.GroupID})
+}
+
+func logGroupErrors(group sarama.ConsumerGroup) {
+	for err := range group.Errors() {
+		logger.Log.Error("Consumer group error", logger.Field{Key: "error", Value: err})
+	}
+}
+
+// setAssignment records the partitions claimed by a new session, or clears them when it ends
+func (c *Consumer) setAssignment(claims map[string][]int32) {
+	assignment := make(map[string][]int32, len(claims))
+	for topic, partitions := range claims {
+		assignment[topic] = append([]int32(nil), partitions...)
+	}
+
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	c.control.assignment = assignment
+}
+
+// applyPause pauses fetching for a newly claimed partition that was paused before the rebalance
+func (c *Consumer) applyPause(topic string, partition int32) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	if c.control.isPaused(topic, partition) && c.consumerGroup != nil {
+		c.consumerGroup.Pause(map[string][]int32{topic: {partition}})
+	}
+}
+
+// resumeUnlessPaused resumes fetching a partition held back internally, unless an operator paused it
+func (c *Consumer) resumeUnlessPaused(topic string, partition int32) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	if !c.control.isPaused(topic, partition) && c.consumerGroup != nil {
+		c.consumerGroup.Resume(map[string][]int32{topic: {partition}})
+	}
+}
+
+// pauseFetching stops fetching a partition without recording an operator pause
+func (c *Consumer) pauseFetching(topic string, partition int32) {
+	c.control.mu.Lock()
+	defer c.control.mu.Unlock()
+	if c.consumerGroup != nil {
+		c.consumerGroup.Pause(map[string][]int32{topic: {partition}})
+	}
+}
+
+// waitWhilePaused holds processing of a paused partition. Messages fetched before the pause
+// would otherwise still be processed. Returns false if the session ended first.
+func (h *consumerGroupHandler) waitWhilePaused(session sarama.ConsumerGroupSession, topic string, partition int32) bool {
+	control := &h.consumer.control
+	for {
+		control.mu.Lock()
+		if !control.isPaused(topic, partition) {
+			control.mu.Unlock()
+			return true
+		}
+		resumed := control.resumed
+		control.mu.Unlock()
+
+		select {
+		case <-resumed:
+		case <-session.Context().Done():
+			return false
+		}
+	}
+}
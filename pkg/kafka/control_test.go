@@ -0,0 +1,127 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+func TestConsumerPauseResume(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	type step struct {
+		resume     bool
+		partitions []int32
+	}
+	tests := []struct {
+		name       string
+		steps      []step
+		wantPaused map[string][]int32
+		wantActive map[int32]bool
+	}{
+		{
+			name:       "pause whole topic",
+			steps:      []step{{}},
+			wantPaused: map[string][]int32{"orders": {allPartitions}},
+			wantActive: map[int32]bool{0: false, 1: false},
+		},
+		{
+			name:       "pause one partition",
+			steps:      []step{{partitions: []int32{1}}},
+			wantPaused: map[string][]int32{"orders": {1}},
+			wantActive: map[int32]bool{0: true, 1: false},
+		},
+		{
+			name:       "resume whole topic clears partition pauses",
+			steps:      []step{{partitions: []int32{0, 1}}, {resume: true}},
+			wantPaused: map[string][]int32{},
+			wantActive: map[int32]bool{0: true, 1: true},
+		},
+		{
+			name:       "resuming a partition of a topic paused as a whole leaves it paused",
+			steps:      []step{{}, {resume: true, partitions: []int32{1}}},
+			wantPaused: map[string][]int32{"orders": {allPartitions}},
+			wantActive: map[int32]bool{0: false, 1: false},
+		},
+		{
+			name:       "resume one of two paused partitions",
+			steps:      []step{{partitions: []int32{0, 1}}, {resume: true, partitions: []int32{0}}},
+			wantPaused: map[string][]int32{"orders": {1}},
+			wantActive: map[int32]bool{0: true, 1: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Consumer{control: newConsumerControl()}
+			c.control.assignment = map[string][]int32{"orders": {0, 1}}
+
+			for _, s := range tt.steps {
+				if s.resume {
+					c.Resume("orders", s.partitions)
+				} else {
+					c.Pause("orders", s.partitions)
+				}
+			}
+
+			if got := c.State().Paused; !reflect.DeepEqual(got, tt.wantPaused) {
+				t.Errorf("Paused = %v, want %v", got, tt.wantPaused)
+			}
+			for partition, active := range tt.wantActive {
+				if paused := c.control.isPaused("orders", partition); paused == active {
+					t.Errorf("partition %d paused = %v, want %v", partition, paused, !active)
+				}
+			}
+		})
+	}
+}
+
+// offsetClient serves fixed partition offsets; other sarama.Client methods are not used
+type offsetClient struct {
+	sarama.Client
+	oldest, newest int64
+	atTime         int64
+}
+
+func (c *offsetClient) GetOffset(_ string, _ int32, time int64) (int64, error) {
+	switch time {
+	case sarama.OffsetOldest:
+		return c.oldest, nil
+	case sarama.OffsetNewest:
+		return c.newest, nil
+	}
+	return c.atTime, nil
+}
+
+func TestResolveOffset(t *testing.T) {
+	offset := func(o int64) *int64 { return &o }
+
+	tests := []struct {
+		name   string
+		reset  OffsetReset
+		atTime int64
+		want   int64
+	}{
+		{name: "offset within range", reset: OffsetReset{Offset: offset(150)}, want: 150},
+		{name: "offset before retention is clamped to oldest", reset: OffsetReset{Offset: offset(10)}, want: 100},
+		{name: "offset past the end is clamped to newest", reset: OffsetReset{Offset: offset(500)}, want: 200},
+		{name: "timestamp", atTime: 120, want: 120},
+		{name: "timestamp after the last message", atTime: -1, want: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := &GroupAdmin{client: &offsetClient{oldest: 100, newest: 200, atTime: tt.atTime}}
+			got, err := admin.resolveOffset("orders", 0, tt.reset)
+			if err != nil {
+				t.Fatalf("resolveOffset() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// ErrMessageInProgress is returned by DedupStore.Claim while another consumer holds the claim
+var ErrMessageInProgress = errors.New("message is being processed by another consumer")
+
+// DedupStore remembers which messages a consumer group has processed or is processing
+type DedupStore interface {
+	// Claim atomically reserves id for processing. It returns false if id was already
+	// processed, and ErrMessageInProgress while another claim on it has not expired.
+	Claim(ctx context.Context, id string) (bool, error)
+	// Complete records a claimed id as processed
+	Complete(ctx context.Context, id string) error
+	// Release drops a claim so the message can be processed again
+	Release(ctx context.Context, id string) error
+}
+
+// Deduplicate claims each message before running handler and skips messages already processed,
+// so concurrent redeliveries cannot both run it. Messages are identified by event ID, falling
+// back to the offset they were first consumed at, so retry-topic and rebalance redeliveries are
+// both caught. A failed handler releases its claim for the retry; a handler that dies without
+// releasing blocks redeliveries until the claim times out. Effects are exactly-once only when
+// handlers finish within the claim timeout and the completion is recorded; otherwise a message
+// may run again, so side effects should stay idempotent.
+// A nil store returns handler unchanged.
+func Deduplicate(store DedupStore, handler MessageHandler) MessageHandler {
+	if store == nil {
+		return handler
+	}
+	return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		id := dedupID(message)
+
+		claimed, err := store.Claim(ctx, id)
+		if err != nil {
+			// Retry rather than risk a duplicate side effect
+			return fmt.Errorf("dedup claim failed for %s: %w", id, err)
+		}
+		if !claimed {
+			logger.Log.Info("Skipping already processed message",
+				logger.Field{Key: "topic", Value: message.Topic},
+				logger.Field{Key: "offset", Value: message.Offset},
+				logger.Field{Key: "dedupId", Value: id})
+			return nil
+		}
+
+		if err := handler(ctx, message); err != nil {
+			// Let the retry claim it again; ctx may be cancelled during shutdown
+			if releaseErr := store.Release(context.WithoutCancel(ctx), id); releaseErr != nil {
+				logger.Log.Warn("Failed to release dedup claim, redeliveries wait for it to time out",
+					logger.Field{Key: "dedupId", Value: id},
+					logger.Field{Key: "error", Value: releaseErr})
+			}
+			return err
+		}
+
+		if err := store.Complete(context.WithoutCancel(ctx), id); err != nil {
+			// The handler succeeded; failing now would only cause the duplicate we try to avoid.
+			// The claim still blocks redeliveries until it times out.
+			logger.Log.Warn("Failed to record processed message",
+				logger.Field{Key: "dedupId", Value: id},
+				logger.Field{Key: "error", Value: err})
+		}
+		return nil
+	}
+}
+
+func dedupID(message *sarama.ConsumerMessage) string {
+	if id := headerValue(message.Headers, HeaderEventID); id != "" {
+		return "event:" + id
+	}
+	if id := headerValue(message.Headers, cloudEventsHeaderPrefix+"id"); id != "" {
+		return "event:" + id
+	}
+	topic, partition, offset := messageOrigin(message)
+	return fmt.Sprintf("offset:%s/%s/%s", topic, partition, offset)
+}
+
+// messageOrigin returns where a message was first consumed: the original position recorded
+// on retry-topic copies, or the message's own position
+func messageOrigin(message *sarama.ConsumerMessage) (topic, partition, offset string) {
+	if topic := headerValue(message.Headers, HeaderOriginalTopic); topic != "" {
+		return topic, headerValue(message.Headers, HeaderOriginalPartition), headerValue(message.Headers, HeaderOriginalOffset)
+	}
+	return message.Topic, strconv.Itoa(int(message.Partition)), strconv.FormatInt(message.Offset, 10)
+}
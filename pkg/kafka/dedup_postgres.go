@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresDedupStore keeps processed message IDs in the processed_messages table,
+// surviving Redis evictions and restarts. A claimed row has claimed_until set until the
+// handler completes.
+type PostgresDedupStore struct {
+	db           *pgxpool.Pool
+	groupID      string
+	ttl          time.Duration
+	claimTimeout time.Duration
+}
+
+// NewPostgresDedupStore scopes IDs by consumer group. IDs older than ttl are treated as
+// unseen; 0 keeps them forever. Claims older than claimTimeout are taken over.
+func NewPostgresDedupStore(db *pgxpool.Pool, groupID string, ttl, claimTimeout time.Duration) *PostgresDedupStore {
+	return &PostgresDedupStore{db: db, groupID: groupID, ttl: ttl, claimTimeout: claimTimeout}
+}
+
+func (s *PostgresDedupStore) Claim(ctx context.Context, id string) (bool, error) {
+	// The insert wins for a new ID; an existing row is only taken over once its claim went stale
+	// or its processed record expired
+	query := `INSERT INTO processed_messages (consumer_group, message_id, processed_at, claimed_until)
+	VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3::bigint))
+	ON CONFLICT (consumer_group, message_id) DO UPDATE
+	SET processed_at = EXCLUDED.processed_at, claimed_until = EXCLUDED.claimed_until
+	WHERE processed_messages.claimed_until < NOW()
+	OR (processed_messages.claimed_until IS NULL AND $4::bigint > 0 AND processed_messages.processed_at <= NOW() - make_interval(secs => $4::bigint))
+	RETURNING true`
+	var claimed bool
+	err := s.db.QueryRow(ctx, query, s.groupID, id, int64(s.claimTimeout.Seconds()), int64(s.ttl.Seconds())).Scan(&claimed)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	var processed bool
+	err = s.db.QueryRow(ctx, `SELECT claimed_until IS NULL FROM processed_messages WHERE consumer_group = $1 AND message_id = $2`,
+		s.groupID, id).Scan(&processed)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !processed) {
+		return false, ErrMessageInProgress
+	}
+	return false, err
+}
+
+func (s *PostgresDedupStore) Complete(ctx context.Context, id string) error {
+	query := `UPDATE processed_messages SET processed_at = NOW(), claimed_until = NULL
+	WHERE consumer_group = $1 AND message_id = $2`
+	_, err := s.db.Exec(ctx, query, s.groupID, id)
+	return err
+}
+
+func (s *PostgresDedupStore) Release(ctx context.Context, id string) error {
+	query := `DELETE FROM processed_messages WHERE consumer_group = $1 AND message_id = $2 AND claimed_until IS NOT NULL`
+	_, err := s.db.Exec(ctx, query, s.groupID, id)
+	return err
+}
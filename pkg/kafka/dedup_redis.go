@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go1/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const dedupProcessing = "processing"
+
+// RedisDedupStore keeps processed message IDs in Redis for a limited time. A claimed ID holds
+// "processing" until the handler completes; any other value means processed.
+type RedisDedupStore struct {
+	rd           *redis.RedisClient
+	prefix       string
+	ttl          time.Duration
+	claimTimeout time.Duration
+}
+
+// NewRedisDedupStore scopes IDs by consumer group; ttl should exceed the longest redelivery window
+// and claimTimeout the slowest handler
+func NewRedisDedupStore(rd *redis.RedisClient, groupID string, ttl, claimTimeout time.Duration) *RedisDedupStore {
+	return &RedisDedupStore{rd: rd, prefix: "kafka:processed:" + groupID + ":", ttl: ttl, claimTimeout: claimTimeout}
+}
+
+func (s *RedisDedupStore) Claim(ctx context.Context, id string) (bool, error) {
+	claimed, err := s.rd.Client.SetNX(ctx, s.prefix+id, dedupProcessing, s.claimTimeout).Result()
+	if err != nil || claimed {
+		return claimed, err
+	}
+
+	state, err := s.rd.Client.Get(ctx, s.prefix+id).Result()
+	if errors.Is(err, goredis.Nil) || state == dedupProcessing {
+		// Released or expired in between counts as in progress: the redelivery retries the claim
+		return false, ErrMessageInProgress
+	}
+	return false, err
+}
+
+func (s *RedisDedupStore) Complete(ctx context.Context, id string) error {
+	return s.rd.Client.Set(ctx, s.prefix+id, "done", s.ttl).Err()
+}
+
+func (s *RedisDedupStore) Release(ctx context.Context, id string) error {
+	return s.rd.Client.Del(ctx, s.prefix+id).Err()
+}
@@ -0,0 +1,151 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// memoryDedupStore claims IDs in memory with the same states as the Redis store
+type memoryDedupStore struct {
+	mu       sync.Mutex
+	states   map[string]string
+	claimErr error
+}
+
+func (s *memoryDedupStore) Claim(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.claimErr != nil {
+		return false, s.claimErr
+	}
+	switch s.states[id] {
+	case "":
+		s.states[id] = dedupProcessing
+		return true, nil
+	case dedupProcessing:
+		return false, ErrMessageInProgress
+	default:
+		return false, nil
+	}
+}
+
+func (s *memoryDedupStore) Complete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[id] = "done"
+	return nil
+}
+
+func (s *memoryDedupStore) Release(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, id)
+	return nil
+}
+
+func TestDeduplicate(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	message := &sarama.ConsumerMessage{Topic: "orders", Partition: 1, Offset: 7,
+		Headers: []*sarama.RecordHeader{{Key: []byte(HeaderEventID), Value: []byte("e1")}}}
+	handlerErr := errors.New("handler failed")
+	storeErr := errors.New("redis down")
+
+	tests := []struct {
+		name      string
+		state     string // Stored state of event:e1 before the call
+		claimErr  error
+		handler   error
+		wantErr   error
+		wantCalls int
+		wantState string
+	}{
+		{name: "new message is processed", wantCalls: 1, wantState: "done"},
+		{name: "processed message is skipped", state: "done", wantState: "done"},
+		{name: "message in progress elsewhere is retried", state: dedupProcessing, wantErr: ErrMessageInProgress, wantState: dedupProcessing},
+		{name: "failed handler releases the claim", handler: handlerErr, wantErr: handlerErr, wantCalls: 1},
+		{name: "store failure is retried", claimErr: storeErr, wantErr: storeErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryDedupStore{states: map[string]string{}, claimErr: tt.claimErr}
+			if tt.state != "" {
+				store.states["event:e1"] = tt.state
+			}
+			calls := 0
+			handler := Deduplicate(store, func(ctx context.Context, message *sarama.ConsumerMessage) error {
+				calls++
+				if got := store.states["event:e1"]; got != dedupProcessing {
+					t.Errorf("state while handling = %q, want claimed", got)
+				}
+				return tt.handler
+			})
+
+			err := handler(context.Background(), message)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+			if got := store.states["event:e1"]; got != tt.wantState {
+				t.Errorf("state = %q, want %q", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestDeduplicateConcurrentRedeliveries(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	store := &memoryDedupStore{states: map[string]string{}}
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	handler := Deduplicate(store, func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return nil
+	})
+
+	// The original and its retry-topic copy share a dedup ID
+	original := &sarama.ConsumerMessage{Topic: "orders", Partition: 0, Offset: 3}
+	retry := &sarama.ConsumerMessage{Topic: "orders.retry.1", Headers: []*sarama.RecordHeader{
+		{Key: []byte(HeaderOriginalTopic), Value: []byte("orders")},
+		{Key: []byte(HeaderOriginalPartition), Value: []byte("0")},
+		{Key: []byte(HeaderOriginalOffset), Value: []byte("3")},
+	}}
+
+	done := make(chan error)
+	go func() { done <- handler(context.Background(), original) }()
+	for {
+		store.mu.Lock()
+		claimed := store.states["offset:orders/0/3"] == dedupProcessing
+		store.mu.Unlock()
+		if claimed {
+			break
+		}
+	}
+	if err := handler(context.Background(), retry); !errors.Is(err, ErrMessageInProgress) {
+		t.Fatalf("concurrent redelivery error = %v, want ErrMessageInProgress", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("original error = %v", err)
+	}
+	if err := handler(context.Background(), retry); err != nil {
+		t.Fatalf("later redelivery error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
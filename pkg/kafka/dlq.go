@@ -0,0 +1,373 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appConfig "go1/config"
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// Headers added to replayed messages
+const (
+	HeaderReplayedBy   = "x-replayed-by"
+	HeaderReplayedAt   = "x-replayed-at"
+	HeaderReplayedFrom = "x-replayed-from" // dlq-topic/partition/offset
+)
+
+// ErrNotDLQTopic is returned when listing or replaying a topic without the DLQ suffix
+var ErrNotDLQTopic = errors.New("not a DLQ topic")
+
+// ErrDLQFilterRequired guards against replaying a whole DLQ by accident
+var ErrDLQFilterRequired = errors.New("refusing to replay the whole DLQ without a filter; set all to confirm")
+
+// dlqReadIdleTimeout ends a partition scan when no message arrives, e.g. at a trailing
+// transaction marker that is never delivered
+const dlqReadIdleTimeout = 5 * time.Second
+
+// DLQRef points at a single DLQ message
+type DLQRef struct {
+	Partition int32 `json:"partition"`
+	Offset    int64 `json:"offset"`
+}
+
+// DLQFilter selects DLQ messages. Empty fields match everything.
+type DLQFilter struct {
+	Key           string    `json:"key,omitempty"`
+	ErrorContains string    `json:"error,omitempty"` // Case-insensitive match on the DLQ reason
+	Since         time.Time `json:"since,omitempty"`
+	Until         time.Time `json:"until,omitempty"`
+	Refs          []DLQRef  `json:"refs,omitempty"` // Exact messages; combined with the other fields
+	Limit         int       `json:"limit,omitempty"`
+	All           bool      `json:"all,omitempty"` // Required to replay without any other criteria
+}
+
+func (f DLQFilter) isEmpty() bool {
+	return f.Key == "" && f.ErrorContains == "" && f.Since.IsZero() && f.Until.IsZero() && len(f.Refs) == 0
+}
+
+func (f DLQFilter) match(m *sarama.ConsumerMessage, reason string) bool {
+	if f.Key != "" && string(m.Key) != f.Key {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(strings.ToLower(reason), strings.ToLower(f.ErrorContains)) {
+		return false
+	}
+	if !f.Since.IsZero() && m.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && m.Timestamp.After(f.Until) {
+		return false
+	}
+	if len(f.Refs) > 0 {
+		for _, ref := range f.Refs {
+			if ref.Partition == m.Partition && ref.Offset == m.Offset {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// DLQMessage is a dead-lettered message with its failure details
+type DLQMessage struct {
+	Topic         string            `json:"topic"`
+	Partition     int32             `json:"partition"`
+	Offset        int64             `json:"offset"`
+	Key           string            `json:"key"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Reason        string            `json:"reason"`
+	Attempts      int               `json:"attempts"`
+	OriginalTopic string            `json:"original_topic"`
+	Headers       map[string]string `json:"headers"`
+	Value         json.RawMessage   `json:"value,omitempty"`     // Set for JSON payloads
+	RawValue      []byte            `json:"raw_value,omitempty"` // Set for other payloads
+	headers       []*sarama.RecordHeader
+	value         []byte
+}
+
+// DLQReplay records a replayed message; it is published to the audit topic
+type DLQReplay struct {
+	DLQTopic    string    `json:"dlq_topic"`
+	Partition   int32     `json:"partition"`
+	Offset      int64     `json:"offset"`
+	Key         string    `json:"key"`
+	Reason      string    `json:"reason"`
+	TargetTopic string    `json:"target_topic"`
+	ReplayedBy  string    `json:"replayed_by"`
+	ReplayedAt  time.Time `json:"replayed_at"`
+}
+
+// DLQOptions configures DLQ inspection and replay
+type DLQOptions struct {
+	// DLQTopicSuffix identifies DLQ topics. Empty defaults to ".dlq".
+	DLQTopicSuffix string
+	// AuditTopic receives a DLQReplay record per replayed message. Empty disables the audit trail.
+	AuditTopic string
+}
+
+// DLQ lists dead-lettered messages and replays them to their original topic
+type DLQ struct {
+	client   sarama.Client
+	producer *KafkaProducer
+	opts     DLQOptions
+}
+
+func NewDLQ(kafkaConfig appConfig.KafkaConfig, opts DLQOptions) (*DLQ, error) {
+	if opts.DLQTopicSuffix == "" {
+		opts.DLQTopicSuffix = ".dlq"
+	}
+
+	config, err := newSaramaConfig(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer.Return.Errors = true
+	client, err := sarama.NewClient(strings.Split(kafkaConfig.Brokers, ","), config)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := NewProducer(kafkaConfig)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &DLQ{client: client, producer: producer, opts: opts}, nil
+}
+
+// List returns the DLQ messages of topic matching filter, oldest first per partition
+func (d *DLQ) List(ctx context.Context, topic string, filter DLQFilter) ([]DLQMessage, error) {
+	if !strings.HasSuffix(topic, d.opts.DLQTopicSuffix) {
+		return nil, fmt.Errorf("%s: %w (suffix %s)", topic, ErrNotDLQTopic, d.opts.DLQTopicSuffix)
+	}
+
+	partitions, err := d.client.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := sarama.NewConsumerFromClient(d.client)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	messages := make([]DLQMessage, 0)
+	for _, partition := range partitions {
+		if filter.Limit > 0 && len(messages) >= filter.Limit {
+			break
+		}
+		found, err := d.scanPartition(ctx, consumer, topic, partition, filter, filter.Limit-len(messages))
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, found...)
+	}
+	return messages, nil
+}
+
+func (d *DLQ) scanPartition(ctx context.Context, consumer sarama.Consumer, topic string, partition int32, filter DLQFilter, limit int) ([]DLQMessage, error) {
+	start, err := d.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return nil, err
+	}
+	end, err := d.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only read the range that can contain the requested messages
+	if len(filter.Refs) > 0 {
+		first, last := int64(-1), int64(-1)
+		for _, ref := range filter.Refs {
+			if ref.Partition != partition {
+				continue
+			}
+			if first < 0 || ref.Offset < first {
+				first = ref.Offset
+			}
+			if ref.Offset > last {
+				last = ref.Offset
+			}
+		}
+		if first < 0 {
+			return nil, nil
+		}
+		start = max(start, first)
+		end = min(end, last+1)
+	}
+	if start >= end {
+		return nil, nil
+	}
+
+	pc, err := consumer.ConsumePartition(topic, partition, start)
+	if err != nil {
+		return nil, err
+	}
+	defer pc.Close()
+
+	var found []DLQMessage
+	idle := time.NewTimer(dlqReadIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case m := <-pc.Messages():
+			if reason := headerValue(m.Headers, HeaderDLQReason); filter.match(m, reason) {
+				found = append(found, toDLQMessage(m, d.opts.DLQTopicSuffix))
+				if limit > 0 && len(found) >= limit {
+					return found, nil
+				}
+			}
+			if m.Offset >= end-1 {
+				return found, nil
+			}
+			idle.Reset(dlqReadIdleTimeout)
+		case err := <-pc.Errors():
+			return nil, err
+		case <-idle.C:
+			return found, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Replay republishes the selected DLQ messages to their original topic with the attempt
+// count reset, and records each replay in headers, the log and the audit topic.
+func (d *DLQ) Replay(ctx context.Context, topic string, filter DLQFilter, replayedBy string) ([]DLQReplay, error) {
+	if replayedBy == "" {
+		return nil, fmt.Errorf("replayedBy is required")
+	}
+	if filter.isEmpty() && !filter.All {
+		return nil, ErrDLQFilterRequired
+	}
+
+	messages, err := d.List(ctx, topic, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	replays := make([]DLQReplay, 0, len(messages))
+	for _, m := range messages {
+		replay := DLQReplay{
+			DLQTopic:    m.Topic,
+			Partition:   m.Partition,
+			Offset:      m.Offset,
+			Key:         m.Key,
+			Reason:      m.Reason,
+			TargetTopic: m.OriginalTopic,
+			ReplayedBy:  replayedBy,
+			ReplayedAt:  time.Now().UTC(),
+		}
+
+		headers := make([]sarama.RecordHeader, 0, len(m.headers)+3)
+		for _, h := range m.headers {
+			switch strings.ToLower(string(h.Key)) {
+			case HeaderAttempt, HeaderNotBefore, HeaderDLQReason, HeaderDLQStack,
+				HeaderReplayedBy, HeaderReplayedAt, HeaderReplayedFrom:
+				continue
+			}
+			headers = append(headers, *h)
+		}
+		headers = append(headers,
+			sarama.RecordHeader{Key: []byte(HeaderReplayedBy), Value: []byte(replayedBy)},
+			sarama.RecordHeader{Key: []byte(HeaderReplayedAt), Value: []byte(replay.ReplayedAt.Format(time.RFC3339))},
+			sarama.RecordHeader{Key: []byte(HeaderReplayedFrom), Value: []byte(fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset))},
+		)
+
+		if err := d.producer.PublishWithHeaders(ctx, m.OriginalTopic, []byte(m.Key), m.value, headers); err != nil {
+			return replays, fmt.Errorf("failed to replay %s/%d/%d: %w", m.Topic, m.Partition, m.Offset, err)
+		}
+		replays = append(replays, replay)
+
+		logger.Log.Info("DLQ message replayed",
+			logger.Field{Key: "dlqTopic", Value: m.Topic},
+			logger.Field{Key: "partition", Value: m.Partition},
+			logger.Field{Key: "offset", Value: m.Offset},
+			logger.Field{Key: "targetTopic", Value: m.OriginalTopic},
+			logger.Field{Key: "replayedBy", Value: replayedBy})
+
+		if d.opts.AuditTopic != "" {
+			if err := d.producer.PublishWithHeaders(ctx, d.opts.AuditTopic, []byte(m.Topic), mustJSON(replay), nil); err != nil {
+				return replays, fmt.Errorf("replayed %s/%d/%d but failed to record audit: %w", m.Topic, m.Partition, m.Offset, err)
+			}
+		}
+	}
+	return replays, nil
+}
+
+func (d *DLQ) Close() {
+	d.producer.Close()
+	d.client.Close()
+}
+
+// ParseDLQRefs parses "partition:offset" pairs separated by commas, e.g. "0:12,1:40"
+func ParseDLQRefs(s string) ([]DLQRef, error) {
+	var refs []DLQRef
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		p, o, ok := strings.Cut(part, ":")
+		partition, err1 := strconv.ParseInt(p, 10, 32)
+		offset, err2 := strconv.ParseInt(o, 10, 64)
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid message reference %q, want partition:offset", part)
+		}
+		refs = append(refs, DLQRef{Partition: int32(partition), Offset: offset})
+	}
+	return refs, nil
+}
+
+func toDLQMessage(m *sarama.ConsumerMessage, dlqSuffix string) DLQMessage {
+	msg := DLQMessage{
+		Topic:         m.Topic,
+		Partition:     m.Partition,
+		Offset:        m.Offset,
+		Key:           string(m.Key),
+		Timestamp:     m.Timestamp,
+		Reason:        headerValue(m.Headers, HeaderDLQReason),
+		Attempts:      getAttempts(m.Headers),
+		OriginalTopic: headerValue(m.Headers, HeaderOriginalTopic),
+		Headers:       make(map[string]string, len(m.Headers)),
+		headers:       m.Headers,
+		value:         m.Value,
+	}
+	for _, h := range m.Headers {
+		msg.Headers[string(h.Key)] = string(h.Value)
+	}
+	if msg.OriginalTopic == "" {
+		// Dead-lettered before origin headers existed
+		msg.OriginalTopic = strings.TrimSuffix(m.Topic, dlqSuffix)
+	}
+	if json.Valid(m.Value) {
+		msg.Value = m.Value
+	} else {
+		msg.RawValue = m.Value
+	}
+	return msg
+}
+
+func headerValue(hdrs []*sarama.RecordHeader, key string) string {
+	for _, h := range hdrs {
+		if strings.EqualFold(string(h.Key), key) {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
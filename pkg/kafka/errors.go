@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"errors"
+	"runtime/debug"
+)
+
+// maxStackHeaderBytes caps the stack trace recorded in DLQ headers
+const maxStackHeaderBytes = 4096
+
+// classifiedError is implemented by errors marked with Permanent or Retryable.
+// The outermost mark in an error chain wins.
+type classifiedError interface {
+	error
+	Permanent() bool
+}
+
+// permanentError is a failure that no retry can fix, e.g. an undecodable payload
+type permanentError struct {
+	err   error
+	stack []byte
+}
+
+func (e *permanentError) Error() string   { return e.err.Error() }
+func (e *permanentError) Unwrap() error   { return e.err }
+func (e *permanentError) Permanent() bool { return true }
+
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string   { return e.err.Error() }
+func (e *retryableError) Unwrap() error   { return e.err }
+func (e *retryableError) Permanent() bool { return false }
+
+// Permanent marks err as non-retryable: the consumer sends the message straight to the DLQ.
+// The caller's stack is recorded for the DLQ headers.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err, stack: debug.Stack()}
+}
+
+// Retryable marks err as transient. Unmarked errors are already retried; use this to
+// override a Permanent error further down the chain.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var classified classifiedError
+	return errors.As(err, &classified) && classified.Permanent()
+}
+
+// errorStack returns the stack recorded by Permanent, if any
+func errorStack(err error) string {
+	var permanent *permanentError
+	if !errors.As(err, &permanent) {
+		return ""
+	}
+	stack := permanent.stack
+	if len(stack) > maxStackHeaderBytes {
+		stack = stack[:maxStackHeaderBytes]
+	}
+	return string(stack)
+}
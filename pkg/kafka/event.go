@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Standard event headers, mirrored from the envelope so consumers can route without decoding
+const (
+	HeaderEventID      = "x-event-id"
+	HeaderEventType    = "x-event-type"
+	HeaderEventVersion = "x-event-version"
+)
+
+// Event is the envelope shared by every topic in the system.
+// Consumers branch on Type and Version to decode Data.
+type Event[T any] struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Version     int       `json:"version"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	AggregateID string    `json:"aggregate_id"`
+	Producer    string    `json:"producer"`
+	TraceParent string    `json:"traceparent,omitempty"`
+	TraceState  string    `json:"tracestate,omitempty"`
+	Data        T         `json:"data"`
+}
+
+// NewEvent creates an envelope with a fresh ID and the current time.
+// The aggregate ID is also used as the message key to keep per-aggregate ordering.
+func NewEvent[T any](eventType string, version int, aggregateID string, data T) Event[T] {
+	return Event[T]{
+		ID:          ulid.Make().String(),
+		Type:        eventType,
+		Version:     version,
+		OccurredAt:  time.Now().UTC(),
+		AggregateID: aggregateID,
+		Data:        data,
+	}
+}
+
+// PublishEvent publishes an event envelope keyed by its aggregate ID. Missing ID, time and
+// producer are filled in, and the trace context of ctx is recorded in the envelope.
+// Topics configured for CloudEvents are encoded in their binary or structured content mode.
+func PublishEvent[T any](ctx context.Context, p *KafkaProducer, topic string, event Event[T]) error {
+	key, value, headers, err := encodeEvent(ctx, p, topic, event)
+	if err != nil {
+		return err
+	}
+	return p.PublishWithHeaders(ctx, topic, key, value, headers)
+}
+
+// PublishEventAsync is PublishEvent without waiting for the broker; see PublishAsync
+func PublishEventAsync[T any](ctx context.Context, p *KafkaProducer, topic string, event Event[T]) (*Delivery, error) {
+	key, value, headers, err := encodeEvent(ctx, p, topic, event)
+	if err != nil {
+		return nil, err
+	}
+	return p.PublishAsync(ctx, topic, key, value, headers)
+}
+
+// encodeEvent completes the envelope and encodes it for the topic's content mode
+func encodeEvent[T any](ctx context.Context, p *KafkaProducer, topic string, event Event[T]) ([]byte, []byte, []sarama.RecordHeader, error) {
+	if event.Type == "" {
+		return nil, nil, nil, fmt.Errorf("event type is required")
+	}
+	if event.ID == "" {
+		event.ID = ulid.Make().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if event.Version == 0 {
+		event.Version = 1
+	}
+	if event.Producer == "" {
+		event.Producer = p.clientID
+	}
+	if event.TraceParent == "" {
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+		event.TraceParent = carrier.Get("traceparent")
+		event.TraceState = carrier.Get("tracestate")
+	}
+
+	if mode := p.cloudEvents.modeFor(topic); mode != ContentModeEnvelope {
+		value, headers, err := encodeCloudEvent(event, p.cloudEvents.source, mode)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return []byte(event.AggregateID), value, headers, nil
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return []byte(event.AggregateID), value, eventHeaders(event.ID, event.Type, event.Version), nil
+}
+
+func eventHeaders(id, eventType string, version int) []sarama.RecordHeader {
+	return []sarama.RecordHeader{
+		{Key: []byte(HeaderEventID), Value: []byte(id)},
+		{Key: []byte(HeaderEventType), Value: []byte(eventType)},
+		{Key: []byte(HeaderEventVersion), Value: []byte(strconv.Itoa(version))},
+	}
+}
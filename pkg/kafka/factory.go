@@ -19,30 +19,49 @@ type TopicHandlerConfig struct {
 	Name    string
 	Handler MessageHandler
 	Retry   *TopicRetryConfig
+	Batch   *BatchTopic // Consume the base topic in batches; Handler may then be nil
 }
 
 // BuildFromTopics creates a consumer and handler map from instantiated topic configurations
 func (f *Factory) BuildFromTopics(topicConfigs []TopicHandlerConfig) (*Consumer, map[string]MessageHandler, error) {
-	// Read suffix config from YAML (empty is OK, consumer will use defaults)
+	// Read suffix config from YAML, with the consumer's defaults so derived topic names match
 	retrySuffix := f.config.Kafka.Retry.RetrySuffix
+	if retrySuffix == "" {
+		retrySuffix = ".retry"
+	}
 	dlqSuffix := f.config.Kafka.Retry.DLQSuffix
+	if dlqSuffix == "" {
+		dlqSuffix = ".dlq"
+	}
 
 	// Build topics list (base + retry topics)
 	topics := make([]string, 0, len(topicConfigs)*2)
 	handlers := make(map[string]MessageHandler)
 	topicRetryMap := make(map[string]TopicRetryConfig)
+	batchTopics := make(map[string]BatchTopic)
+	provisioned := make([]ProvisionedTopic, 0, len(topicConfigs))
 
 	for _, tc := range topicConfigs {
 		if tc.Name == "" {
 			continue
 		}
+		if tc.Batch != nil {
+			batchTopics[tc.Name] = *tc.Batch
+			if tc.Handler == nil {
+				// Used for retried items, which are consumed one at a time
+				tc.Handler = SingleFromBatch(tc.Batch.Handler)
+			}
+		}
+
 		// Add base topic
 		topics = append(topics, tc.Name)
 		handlers[tc.Name] = tc.Handler
+		provision := ProvisionedTopic{Name: tc.Name, DLQTopic: tc.Name + dlqSuffix}
 
 		// If retry is enabled, add retry topic and config
 		if tc.Retry != nil {
 			retryTopic := tc.Name + retrySuffix
+			provision.RetryTopic = retryTopic
 			topics = append(topics, retryTopic)
 			handlers[retryTopic] = tc.Handler // Same handler for retry
 
@@ -52,24 +71,31 @@ func (f *Factory) BuildFromTopics(topicConfigs []TopicHandlerConfig) (*Consumer,
 			topicRetryMap[tc.Name] = retryConfig
 			topicRetryMap[retryTopic] = retryConfig
 		}
+		provisioned = append(provisioned, provision)
 	}
 
 	if len(topics) == 0 {
 		return nil, nil, fmt.Errorf("no topics to consume")
 	}
 
-	// Create consumer (defaults will be applied in NewConsumerWithOptions if suffixes are empty)
+	if f.config.Kafka.Provisioning.Enabled {
+		if err := f.provision(provisioned); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Create consumer
 	consumer, err := NewConsumerWithOptions(
-		f.config.Kafka.Brokers,
+		f.config.Kafka, // Brokers, TLS/SASL, consumer settings and producer settings for retry/DLQ publishing
 		topics,
 		ConsumerOptions{
 			GroupID:          f.config.Kafka.GroupID,
 			RetryTopicSuffix: retrySuffix,
 			DLQTopicSuffix:   dlqSuffix,
 			TopicRetryConfig: topicRetryMap,
+			KeyConcurrency:   f.config.Kafka.Consumer.KeyConcurrency,
+			BatchTopics:      batchTopics,
 		},
-		f.config.Kafka.Producer, // Producer config for retry/DLQ publishing
-		f.config.Kafka.Consumer, // Consumer config for session timeout, heartbeat, etc.
 	)
 	if err != nil {
 		return nil, nil, err
@@ -77,3 +103,13 @@ func (f *Factory) BuildFromTopics(topicConfigs []TopicHandlerConfig) (*Consumer,
 
 	return consumer, handlers, nil
 }
+
+// provision creates the consumed topics before the consumer group joins them
+func (f *Factory) provision(topics []ProvisionedTopic) error {
+	provisioner, err := NewProvisioner(f.config.Kafka)
+	if err != nil {
+		return err
+	}
+	defer provisioner.Close()
+	return provisioner.Provision(topics)
+}
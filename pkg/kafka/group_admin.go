@@ -0,0 +1,211 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appConfig "go1/config"
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// ErrGroupActive is returned when resetting offsets of a group that still has members
+var ErrGroupActive = errors.New("consumer group has active members; stop all of them first")
+
+// PartitionOffset is a consumer group's position in one partition
+type PartitionOffset struct {
+	Topic         string `json:"topic"`
+	Partition     int32  `json:"partition"`
+	Committed     int64  `json:"committed"` // -1 when the group has not committed yet
+	HighWaterMark int64  `json:"highWaterMark"`
+	Lag           int64  `json:"lag"`
+}
+
+// OffsetReset selects the new position: an explicit offset, or the first message at or after a time
+type OffsetReset struct {
+	Offset    *int64
+	Timestamp time.Time
+}
+
+// GroupAdmin reads and resets a consumer group's committed offsets
+type GroupAdmin struct {
+	client  sarama.Client
+	admin   sarama.ClusterAdmin
+	groupID string
+}
+
+func NewGroupAdmin(kafkaConfig appConfig.KafkaConfig) (*GroupAdmin, error) {
+	config, err := newSaramaConfig(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	if config.Version, err = kafkaVersion(kafkaConfig.Consumer); err != nil {
+		return nil, err
+	}
+
+	brokers := strings.Split(kafkaConfig.Brokers, ",")
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := sarama.NewClusterAdmin(brokers, config)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to create kafka cluster admin: %w", err)
+	}
+	return &GroupAdmin{client: client, admin: admin, groupID: kafkaConfig.GroupID}, nil
+}
+
+// Offsets returns the group's committed offset and lag for every partition of topics
+func (a *GroupAdmin) Offsets(topics []string) ([]PartitionOffset, error) {
+	topicPartitions := make(map[string][]int32, len(topics))
+	for _, topic := range topics {
+		partitions, err := a.client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list partitions of %s: %w", topic, err)
+		}
+		topicPartitions[topic] = partitions
+	}
+
+	committed, err := a.admin.ListConsumerGroupOffsets(a.groupID, topicPartitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+
+	offsets := make([]PartitionOffset, 0)
+	for topic, partitions := range topicPartitions {
+		for _, partition := range partitions {
+			hwm, err := a.client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch high water mark of %s/%d: %w", topic, partition, err)
+			}
+			offset := PartitionOffset{Topic: topic, Partition: partition, Committed: -1, HighWaterMark: hwm, Lag: hwm}
+			if block := committed.GetBlock(topic, partition); block != nil && block.Offset >= 0 {
+				offset.Committed = block.Offset
+				offset.Lag = hwm - block.Offset
+			}
+			offsets = append(offsets, offset)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+	return offsets, nil
+}
+
+// ResetOffsets moves the group's committed offsets of topic (all partitions when none are given).
+// Kafka only accepts this while the group is empty, so every member must be stopped first.
+func (a *GroupAdmin) ResetOffsets(topic string, partitions []int32, reset OffsetReset) ([]PartitionOffset, error) {
+	if reset.Offset == nil && reset.Timestamp.IsZero() {
+		return nil, fmt.Errorf("offset or timestamp is required")
+	}
+	if err := a.ensureEmpty(); err != nil {
+		return nil, err
+	}
+
+	if len(partitions) == 0 {
+		var err error
+		if partitions, err = a.client.Partitions(topic); err != nil {
+			return nil, fmt.Errorf("failed to list partitions of %s: %w", topic, err)
+		}
+	}
+
+	offsetManager, err := sarama.NewOffsetManagerFromClient(a.groupID, a.client)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		target, err := a.resolveOffset(topic, partition, reset)
+		if err != nil {
+			return nil, err
+		}
+
+		partitionManager, err := offsetManager.ManagePartition(topic, partition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to manage offsets of %s/%d: %w", topic, partition, err)
+		}
+		// MarkOffset only moves forward and ResetOffset only backward; one of them applies
+		partitionManager.MarkOffset(target, "")
+		partitionManager.ResetOffset(target, "")
+		partitionManager.AsyncClose()
+		targets[partition] = target
+	}
+	// Close commits the marked offsets
+	if err := offsetManager.Close(); err != nil {
+		return nil, err
+	}
+
+	// The offset manager does not report commit failures, so read the offsets back
+	committed, err := a.admin.ListConsumerGroupOffsets(a.groupID, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify reset offsets: %w", err)
+	}
+	reports := make([]PartitionOffset, 0, len(partitions))
+	for _, partition := range partitions {
+		block := committed.GetBlock(topic, partition)
+		if block == nil || block.Offset != targets[partition] {
+			return reports, fmt.Errorf("offset of %s/%d was not reset to %d", topic, partition, targets[partition])
+		}
+		reports = append(reports, PartitionOffset{Topic: topic, Partition: partition, Committed: block.Offset})
+	}
+
+	logger.Log.Info("Consumer group offsets reset",
+		logger.Field{Key: "groupId", Value: a.groupID},
+		logger.Field{Key: "topic", Value: topic},
+		logger.Field{Key: "offsets", Value: reports})
+	return reports, nil
+}
+
+// resolveOffset returns the offset to reset a partition to, clamped to the retained range
+func (a *GroupAdmin) resolveOffset(topic string, partition int32, reset OffsetReset) (int64, error) {
+	oldest, err := a.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, err
+	}
+	newest, err := a.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, err
+	}
+
+	target := newest
+	if reset.Offset != nil {
+		target = *reset.Offset
+	} else if offset, err := a.client.GetOffset(topic, partition, reset.Timestamp.UnixMilli()); err != nil {
+		return 0, err
+	} else if offset >= 0 {
+		// -1 means no message at or after the timestamp; the end of the partition is then the position
+		target = offset
+	}
+	return min(max(target, oldest), newest), nil
+}
+
+func (a *GroupAdmin) ensureEmpty() error {
+	groups, err := a.admin.DescribeConsumerGroups([]string{a.groupID})
+	if err != nil {
+		return fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	for _, group := range groups {
+		if group.State != "Empty" && group.State != "Dead" {
+			return fmt.Errorf("%w (state %s, %d member(s))", ErrGroupActive, group.State, len(group.Members))
+		}
+	}
+	return nil
+}
+
+func (a *GroupAdmin) Close() {
+	if a.admin != nil {
+		a.admin.Close()
+	}
+	if a.client != nil {
+		a.client.Close()
+	}
+}
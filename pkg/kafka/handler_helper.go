@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"go1/pkg/logger"
@@ -10,6 +11,9 @@ import (
 	"github.com/IBM/sarama"
 )
 
+// errNotAnEvent rejects JSON that decodes but carries no event envelope
+var errNotAnEvent = errors.New("message is not an event envelope: id and type are required")
+
 type TypedMessageHandler[T any] func(ctx context.Context, message T, metadata *MessageMetadata) error
 
 type MessageMetadata struct {
@@ -21,17 +25,45 @@ type MessageMetadata struct {
 	Timestamp int64
 }
 
+// Header returns the value of the first header named key, or "" if absent
+func (m *MessageMetadata) Header(key string) string {
+	for _, h := range m.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// HandleJSON creates a MessageHandler that unmarshals the message value into T.
+// For structured CloudEvents the event data is unmarshaled instead of the whole value.
+// Tombstones are skipped; undecodable messages fail with a Permanent error and are dead-lettered.
 func HandleJSON[T any](typedHandler TypedMessageHandler[T]) MessageHandler {
+	return handleTyped(decodeJSON[T], typedHandler)
+}
+
+// handleTyped decodes the message, logs it and calls the typed handler
+func handleTyped[T any](decode func(*sarama.ConsumerMessage) (T, error), typedHandler TypedMessageHandler[T]) MessageHandler {
 	return func(ctx context.Context, message *sarama.ConsumerMessage) error {
-		var payload T
-		if err := json.Unmarshal(message.Value, &payload); err != nil {
+		// Tombstones (nil value) only tell compaction to drop the key; there is nothing to decode
+		if message.Value == nil {
+			logger.Log.Debug("Skipping tombstone",
+				logger.Field{Key: "topic", Value: message.Topic},
+				logger.Field{Key: "offset", Value: message.Offset},
+				logger.Field{Key: "key", Value: string(message.Key)})
+			return nil
+		}
+
+		payload, err := decode(message)
+		if err != nil {
 			logger.Log.Error("Failed to unmarshal message",
 				logger.Field{Key: "topic", Value: message.Topic},
 				logger.Field{Key: "partition", Value: message.Partition},
 				logger.Field{Key: "offset", Value: message.Offset},
 				logger.Field{Key: "error", Value: err},
 				logger.Field{Key: "raw_value", Value: string(message.Value)})
-			return nil
+			// Poison message: no retry can decode it, so it goes straight to the DLQ
+			return Permanent(fmt.Errorf("failed to decode message: %w", err))
 		}
 
 		// Extract metadata
@@ -47,7 +79,7 @@ func HandleJSON[T any](typedHandler TypedMessageHandler[T]) MessageHandler {
 		// Get attempt count from headers for logging
 		attemptCount := 0
 		for _, h := range message.Headers {
-			if string(h.Key) == "x-attempt" {
+			if string(h.Key) == HeaderAttempt {
 				fmt.Sscanf(string(h.Value), "%d", &attemptCount)
 				break
 			}
@@ -78,6 +110,140 @@ func HandleJSON[T any](typedHandler TypedMessageHandler[T]) MessageHandler {
 	}
 }
 
+// TypedEventHandler handles a decoded event envelope; branch on event.Version to read Data
+type TypedEventHandler[T any] func(ctx context.Context, event Event[T], metadata *MessageMetadata) error
+
+// EventOptions configures how HandleEventWithOptions decodes messages
+type EventOptions struct {
+	// RequireEnvelope rejects messages without an event id and type as Permanent. When false,
+	// a plain JSON payload is accepted as the event data so producers that do not send the
+	// envelope yet keep working while they migrate.
+	RequireEnvelope bool
+}
+
+// HandleEvent creates a MessageHandler that decodes the standard Event envelope with a typed payload
+// CloudEvents in binary or structured content mode are mapped onto the same envelope.
+// Plain JSON payloads are accepted too; see EventOptions.
+func HandleEvent[T any](handler TypedEventHandler[T]) MessageHandler {
+	return HandleEventWithOptions(EventOptions{}, handler)
+}
+
+// HandleEventWithOptions is HandleEvent with explicit decoding options
+func HandleEventWithOptions[T any](opts EventOptions, handler TypedEventHandler[T]) MessageHandler {
+	decode := func(message *sarama.ConsumerMessage) (Event[T], error) {
+		return decodeEvent[T](message, opts.RequireEnvelope)
+	}
+	return handleTyped(decode, TypedMessageHandler[Event[T]](handler))
+}
+
+func decodeJSON[T any](message *sarama.ConsumerMessage) (T, error) {
+	var payload T
+	value := message.Value
+	ce, ok, err := decodeCloudEvent(message)
+	if err != nil {
+		return payload, err
+	}
+	if ok {
+		value = ce.Data
+	}
+	err = json.Unmarshal(value, &payload)
+	return payload, err
+}
+
+// decodeEvent decodes an Event envelope or a CloudEvent. Messages without an event id and
+// type are rejected when the envelope is required, and otherwise decoded as a plain payload.
+func decodeEvent[T any](message *sarama.ConsumerMessage, requireEnvelope bool) (Event[T], error) {
+	event, err := decodeEventEnvelope[T](message)
+	if err != nil {
+		return event, err
+	}
+	if event.ID != "" && event.Type != "" {
+		return event, nil
+	}
+	if _, isCloudEvent, _ := decodeCloudEvent(message); requireEnvelope || isCloudEvent {
+		return event, errNotAnEvent
+	}
+	return decodePlainEvent[T](message)
+}
+
+// decodePlainEvent wraps a payload sent without the envelope. The ID is the position the
+// message was first consumed at, so retry copies keep it, and the type is that topic.
+func decodePlainEvent[T any](message *sarama.ConsumerMessage) (Event[T], error) {
+	topic, partition, offset := messageOrigin(message)
+	event := Event[T]{
+		ID:          fmt.Sprintf("%s/%s/%s", topic, partition, offset),
+		Type:        topic,
+		Version:     1,
+		OccurredAt:  message.Timestamp,
+		AggregateID: string(message.Key),
+	}
+	err := json.Unmarshal(message.Value, &event.Data)
+	return event, err
+}
+
+func decodeEventEnvelope[T any](message *sarama.ConsumerMessage) (Event[T], error) {
+	var event Event[T]
+	ce, ok, err := decodeCloudEvent(message)
+	if err != nil {
+		return event, err
+	}
+	if !ok {
+		err = json.Unmarshal(message.Value, &event)
+		return event, err
+	}
+
+	raw := ce.toEvent()
+	event = Event[T]{
+		ID:          raw.ID,
+		Type:        raw.Type,
+		Version:     raw.Version,
+		OccurredAt:  raw.OccurredAt,
+		AggregateID: raw.AggregateID,
+		Producer:    raw.Producer,
+		TraceParent: raw.TraceParent,
+		TraceState:  raw.TraceState,
+	}
+	err = json.Unmarshal(raw.Data, &event.Data)
+	return event, err
+}
+
+// HandleEventTypes routes messages of a topic carrying several event types to the handler
+// registered for their type. Messages of unregistered types are skipped.
+func HandleEventTypes(handlers map[string]MessageHandler) MessageHandler {
+	return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		eventType := eventTypeOf(message)
+		handler, ok := handlers[eventType]
+		if !ok {
+			logger.Log.Debug("Skipping unhandled event type",
+				logger.Field{Key: "topic", Value: message.Topic},
+				logger.Field{Key: "eventType", Value: eventType})
+			return nil
+		}
+		return handler(ctx, message)
+	}
+}
+
+// eventTypeOf reads the event type from headers, falling back to the envelope
+func eventTypeOf(message *sarama.ConsumerMessage) string {
+	ceType := ""
+	for _, h := range message.Headers {
+		switch string(h.Key) {
+		case HeaderEventType:
+			return string(h.Value)
+		case cloudEventsHeaderPrefix + "type":
+			ceType = string(h.Value)
+		}
+	}
+	if ceType != "" {
+		return ceType
+	}
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(message.Value, &envelope)
+	return envelope.Type
+}
+
 // HandleRaw creates a MessageHandler that passes the raw message without unmarshaling
 // Use this when you need full control over the message
 func HandleRaw(handler MessageHandler) MessageHandler {
@@ -0,0 +1,208 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+type testPayload struct {
+	OrderID string `json:"order_id"`
+}
+
+func header(key, value string) *sarama.RecordHeader {
+	return &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	occurredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		message         *sarama.ConsumerMessage
+		requireEnvelope bool
+		want            Event[testPayload]
+		wantErr         bool
+	}{
+		{
+			name: "envelope",
+			message: &sarama.ConsumerMessage{
+				Value: []byte(`{"id":"e1","type":"OrderCreated","version":2,"occurred_at":"2026-01-02T03:04:05Z","aggregate_id":"o1","producer":"go1","data":{"order_id":"o1"}}`),
+			},
+			want: Event[testPayload]{ID: "e1", Type: "OrderCreated", Version: 2, OccurredAt: occurredAt, AggregateID: "o1", Producer: "go1", Data: testPayload{OrderID: "o1"}},
+		},
+		{
+			name: "binary cloud event",
+			message: &sarama.ConsumerMessage{
+				Headers: []*sarama.RecordHeader{
+					header("ce_specversion", "1.0"),
+					header("ce_id", "e2"),
+					header("ce_type", "OrderCreated"),
+					header("ce_source", "go1-api"),
+					header("ce_subject", "o2"),
+					header("ce_time", "2026-01-02T03:04:05Z"),
+					header("ce_eventversion", "3"),
+					header("content-type", "application/json"),
+				},
+				Value: []byte(`{"order_id":"o2"}`),
+			},
+			want: Event[testPayload]{ID: "e2", Type: "OrderCreated", Version: 3, OccurredAt: occurredAt, AggregateID: "o2", Producer: "go1-api", Data: testPayload{OrderID: "o2"}},
+		},
+		{
+			name: "structured cloud event",
+			message: &sarama.ConsumerMessage{
+				Headers: []*sarama.RecordHeader{header("content-type", "application/cloudevents+json; charset=utf-8")},
+				Value:   []byte(`{"specversion":"1.0","id":"e3","source":"go1-api","type":"OrderCreated","producer":"go1","data":{"order_id":"o3"}}`),
+			},
+			want: Event[testPayload]{ID: "e3", Type: "OrderCreated", Version: 1, Producer: "go1", Data: testPayload{OrderID: "o3"}},
+		},
+		{
+			name:    "plain JSON is wrapped",
+			message: &sarama.ConsumerMessage{Topic: "dispatch-events", Partition: 2, Offset: 7, Key: []byte("o4"), Timestamp: occurredAt, Value: []byte(`{"order_id":"o4"}`)},
+			want:    Event[testPayload]{ID: "dispatch-events/2/7", Type: "dispatch-events", Version: 1, OccurredAt: occurredAt, AggregateID: "o4", Data: testPayload{OrderID: "o4"}},
+		},
+		{
+			name: "plain JSON retry copy keeps the original position",
+			message: &sarama.ConsumerMessage{
+				Topic: "dispatch-events.retry", Offset: 1,
+				Headers: []*sarama.RecordHeader{
+					header(HeaderOriginalTopic, "dispatch-events"),
+					header(HeaderOriginalPartition, "2"),
+					header(HeaderOriginalOffset, "7"),
+				},
+				Value: []byte(`{"order_id":"o4"}`),
+			},
+			want: Event[testPayload]{ID: "dispatch-events/2/7", Type: "dispatch-events", Version: 1, Data: testPayload{OrderID: "o4"}},
+		},
+		{
+			name:            "plain JSON with the envelope required",
+			message:         &sarama.ConsumerMessage{Value: []byte(`{"order_id":"o4"}`)},
+			requireEnvelope: true,
+			wantErr:         true,
+		},
+		{
+			name:            "envelope without type with the envelope required",
+			message:         &sarama.ConsumerMessage{Value: []byte(`{"id":"e5","data":{"order_id":"o5"}}`)},
+			requireEnvelope: true,
+			wantErr:         true,
+		},
+		{
+			name: "binary cloud event without id",
+			message: &sarama.ConsumerMessage{
+				Headers: []*sarama.RecordHeader{header("ce_specversion", "1.0"), header("ce_type", "OrderCreated")},
+				Value:   []byte(`{"order_id":"o6"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			message: &sarama.ConsumerMessage{Value: []byte(`not json`)},
+			wantErr: true,
+		},
+		{
+			name: "invalid structured cloud event",
+			message: &sarama.ConsumerMessage{
+				Headers: []*sarama.RecordHeader{header("content-type", "application/cloudevents+json")},
+				Value:   []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeEvent[testPayload](tt.message, tt.requireEnvelope)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeEvent() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeEvent() error = %v", err)
+			}
+			if !got.OccurredAt.Equal(tt.want.OccurredAt) {
+				t.Errorf("OccurredAt = %v, want %v", got.OccurredAt, tt.want.OccurredAt)
+			}
+			got.OccurredAt, tt.want.OccurredAt = time.Time{}, time.Time{}
+			if got != tt.want {
+				t.Errorf("decodeEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEventWithOptions(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	tests := []struct {
+		name          string
+		opts          EventOptions
+		wantCalled    bool
+		wantPermanent bool
+	}{
+		{name: "plain payload accepted by default", wantCalled: true},
+		{name: "plain payload rejected when the envelope is required", opts: EventOptions{RequireEnvelope: true}, wantPermanent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Event[testPayload]
+			called := false
+			handler := HandleEventWithOptions(tt.opts, func(ctx context.Context, event Event[testPayload], metadata *MessageMetadata) error {
+				called = true
+				got = event
+				return nil
+			})
+
+			err := handler(context.Background(), &sarama.ConsumerMessage{Topic: "orders", Value: []byte(`{"order_id":"o1"}`)})
+			if IsPermanent(err) != tt.wantPermanent || (!tt.wantPermanent && err != nil) {
+				t.Fatalf("handler error = %v, want permanent %v", err, tt.wantPermanent)
+			}
+			if called != tt.wantCalled {
+				t.Fatalf("handler called = %v, want %v", called, tt.wantCalled)
+			}
+			if called && got.Data.OrderID != "o1" {
+				t.Errorf("event data = %+v, want order o1", got.Data)
+			}
+		})
+	}
+}
+
+func TestHandleJSON(t *testing.T) {
+	logger.SetLogger(logger.NewZapLogger("production"))
+
+	tests := []struct {
+		name          string
+		value         []byte
+		wantCalled    bool
+		wantPermanent bool
+	}{
+		{name: "payload", value: []byte(`{"order_id":"o1"}`), wantCalled: true},
+		{name: "tombstone is skipped", value: nil},
+		{name: "empty value is not a tombstone", value: []byte{}, wantPermanent: true},
+		{name: "undecodable", value: []byte(`not json`), wantPermanent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := HandleJSON(func(ctx context.Context, payload testPayload, metadata *MessageMetadata) error {
+				called = true
+				return nil
+			})
+
+			err := handler(context.Background(), &sarama.ConsumerMessage{Topic: "dbserver1.public.orders", Key: []byte(`{"id":"o1"}`), Value: tt.value})
+			if tt.wantPermanent != IsPermanent(err) || (!tt.wantPermanent && err != nil) {
+				t.Fatalf("handler error = %v, want permanent %v", err, tt.wantPermanent)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
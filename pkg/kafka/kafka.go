@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	appConfig "go1/config"
 	"go1/pkg/logger"
@@ -13,11 +15,31 @@ import (
 )
 
 type KafkaProducer struct {
-	producer sarama.SyncProducer
+	producer    sarama.SyncProducer // nil in async mode
+	async       *asyncSender        // Set when kafka.producer.async.enabled
+	txnMu       sync.Mutex          // Held while a transaction is open
+	clientID    string
+	idempotent  bool
+	cloudEvents *cloudEventsModes
+	schemas     *SchemaRegistry
 }
 
-func NewProducer(brokers string, producerConfig appConfig.KafkaProducerConfig) (*KafkaProducer, error) {
-	config := sarama.NewConfig()
+// NewProducer connects a producer using the brokers, TLS/SASL and producer settings of kafkaConfig.
+// In async mode messages are batched in the background; Publish and PublishWithHeaders still
+// wait for their own delivery, PublishAsync does not.
+func NewProducer(kafkaConfig appConfig.KafkaConfig) (*KafkaProducer, error) {
+	return newProducer(kafkaConfig, "")
+}
+
+func newProducer(kafkaConfig appConfig.KafkaConfig, transactionalID string) (*KafkaProducer, error) {
+	producerConfig := kafkaConfig.Producer
+	config, err := newSaramaConfig(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	if producerConfig.ClientID != "" {
+		config.ClientID = producerConfig.ClientID
+	}
 
 	// Configure RequiredAcks
 	switch producerConfig.RequiredAcks {
@@ -56,21 +78,74 @@ func NewProducer(brokers string, producerConfig appConfig.KafkaProducerConfig) (
 		config.Producer.MaxMessageBytes = producerConfig.MaxMessageBytes
 	}
 
-	config.Producer.Return.Successes = true // Required for SyncProducer
+	// Idempotence keeps broker-side retries from writing duplicates; transactions build on it
+	if producerConfig.Idempotent || transactionalID != "" {
+		config.Producer.Idempotent = true
+		config.Producer.RequiredAcks = sarama.WaitForAll
+		config.Net.MaxOpenRequests = 1
+	}
+	if transactionalID != "" {
+		config.Producer.Transaction.ID = transactionalID
+	}
 
-	brokerList := strings.Split(brokers, ",")
-	producer, err := sarama.NewSyncProducer(brokerList, config)
-	if err != nil {
-		return nil, err
+	config.Producer.Return.Successes = true // Required for SyncProducer and async delivery reports
+
+	brokerList := strings.Split(kafkaConfig.Brokers, ",")
+	kafkaProducer := &KafkaProducer{clientID: config.ClientID, idempotent: config.Producer.Idempotent}
+	if async := producerConfig.Async; async.Enabled {
+		// Configure batching
+		config.Producer.Flush.Frequency = time.Duration(async.LingerMs) * time.Millisecond
+		config.Producer.Flush.Messages = async.BatchSize
+		config.Producer.Flush.Bytes = async.BatchBytes
+
+		producer, err := sarama.NewAsyncProducer(brokerList, config)
+		if err != nil {
+			return nil, err
+		}
+		kafkaProducer.async = newAsyncSender(producer, async.MaxInFlight)
+	} else {
+		producer, err := sarama.NewSyncProducer(brokerList, config)
+		if err != nil {
+			return nil, err
+		}
+		kafkaProducer.producer = producer
 	}
 
 	logger.Log.Info("✅ Connected to Kafka producer",
-		logger.Field{Key: "brokers", Value: brokers},
+		logger.Field{Key: "brokers", Value: kafkaConfig.Brokers},
 		logger.Field{Key: "requiredAcks", Value: producerConfig.RequiredAcks},
 		logger.Field{Key: "retryMax", Value: producerConfig.RetryMax},
-		logger.Field{Key: "compression", Value: producerConfig.Compression})
+		logger.Field{Key: "compression", Value: producerConfig.Compression},
+		logger.Field{Key: "async", Value: producerConfig.Async.Enabled},
+		logger.Field{Key: "idempotent", Value: config.Producer.Idempotent},
+		logger.Field{Key: "transactionalId", Value: transactionalID})
+
+	return kafkaProducer, nil
+}
+
+// send publishes msg and waits for its delivery, in either mode
+func (k *KafkaProducer) send(ctx context.Context, msg *sarama.ProducerMessage) (int32, int64, error) {
+	if k.async == nil {
+		return k.producer.SendMessage(msg)
+	}
 
-	return &KafkaProducer{producer: producer}, nil
+	delivery := &Delivery{done: make(chan struct{})}
+	if err := k.async.send(ctx, msg, &pendingDelivery{delivery: delivery}); err != nil {
+		return 0, 0, err
+	}
+	report, err := delivery.Wait(ctx)
+	return report.Partition, report.Offset, err
+}
+
+// UseCloudEvents makes PublishEvent encode events as CloudEvents on the configured topics.
+// Topics not listed keep the plain Event envelope.
+func (k *KafkaProducer) UseCloudEvents(cfg appConfig.KafkaCloudEventsConfig) error {
+	modes, err := newCloudEventsModes(cfg, k.clientID)
+	if err != nil {
+		return err
+	}
+	k.cloudEvents = modes
+	return nil
 }
 
 // Publish publishes a message to Kafka with automatic logging and JSON marshaling
@@ -105,14 +180,21 @@ func (k *KafkaProducer) Publish(topic string, message any, key ...string) error
 		keyBytes = []byte(key[0])
 	}
 
+	// Start a new trace: Publish has no caller context
+	span, headers := startProducerSpan(context.Background(), topic, nil)
+
 	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Key:   sarama.ByteEncoder(keyBytes),
-		Value: sarama.ByteEncoder(valueBytes),
+		Topic:   topic,
+		Key:     sarama.ByteEncoder(keyBytes),
+		Value:   sarama.ByteEncoder(valueBytes),
+		Headers: headers,
 	}
 
 	// Send message
-	partition, offset, err := k.producer.SendMessage(msg)
+	start := time.Now()
+	partition, offset, err := k.send(context.Background(), msg)
+	observePublish(topic, start, len(keyBytes)+len(valueBytes), err)
+	endProducerSpan(span, partition, offset, err)
 
 	if err != nil {
 		logger.Log.Error("❌ Failed to publish message",
@@ -123,8 +205,7 @@ func (k *KafkaProducer) Publish(topic string, message any, key ...string) error
 		return err
 	}
 
-	// Auto-log success
-	logger.Log.Info("📤 Message published",
+	logger.Log.Debug("📤 Message published",
 		logger.Field{Key: "topic", Value: topic},
 		logger.Field{Key: "partition", Value: partition},
 		logger.Field{Key: "offset", Value: offset},
@@ -134,8 +215,11 @@ func (k *KafkaProducer) Publish(topic string, message any, key ...string) error
 	return nil
 }
 
-// PublishWithHeaders publishes a message with custom headers (used internally for retry/DLQ)
+// PublishWithHeaders publishes a message with custom headers (used internally for retry/DLQ).
+// The trace context of ctx is injected as W3C traceparent, replacing any copied from a consumed message.
 func (k *KafkaProducer) PublishWithHeaders(ctx context.Context, topic string, key, value []byte, headers []sarama.RecordHeader) error {
+	span, headers := startProducerSpan(ctx, topic, headers)
+
 	msg := &sarama.ProducerMessage{
 		Topic:   topic,
 		Key:     sarama.ByteEncoder(key),
@@ -143,7 +227,10 @@ func (k *KafkaProducer) PublishWithHeaders(ctx context.Context, topic string, ke
 		Headers: headers,
 	}
 
-	partition, offset, err := k.producer.SendMessage(msg)
+	start := time.Now()
+	partition, offset, err := k.send(ctx, msg)
+	observePublish(topic, start, len(key)+len(value), err)
+	endProducerSpan(span, partition, offset, err)
 
 	if err != nil {
 		logger.Log.Error("❌ Failed to publish message with headers",
@@ -154,27 +241,44 @@ func (k *KafkaProducer) PublishWithHeaders(ctx context.Context, topic string, ke
 		return err
 	}
 
-	// Log with headers info
-	logger.Log.Info("📤 Message published (with headers)",
+	logger.Log.Debug("📤 Message published (with headers)",
 		logger.Field{Key: "topic", Value: topic},
 		logger.Field{Key: "partition", Value: partition},
 		logger.Field{Key: "offset", Value: offset},
 		logger.Field{Key: "key", Value: string(key)},
+		logger.Field{Key: "attempt", Value: outgoingHeader(headers, HeaderAttempt)},
 		logger.Field{Key: "headers_count", Value: len(headers)})
 
-	// Log each header
+	return nil
+}
+
+// outgoingHeader returns the value of the first header named key, or "" if absent
+func outgoingHeader(headers []sarama.RecordHeader, key string) string {
 	for _, h := range headers {
-		if string(h.Key) == "x-attempt" {
-			logger.Log.Info("  📋 Header",
-				logger.Field{Key: "key", Value: string(h.Key)},
-				logger.Field{Key: "value", Value: string(h.Value)})
+		if string(h.Key) == key {
+			return string(h.Value)
 		}
 	}
+	return ""
+}
 
-	return nil
+// IsAsync reports whether publishes are batched in the background (kafka.producer.async)
+func (k *KafkaProducer) IsAsync() bool {
+	return k.async != nil
+}
+
+// IsIdempotent reports whether broker retries can neither duplicate nor reorder messages
+// of a partition: idempotence allows a single in-flight request per broker
+func (k *KafkaProducer) IsIdempotent() bool {
+	return k.idempotent
 }
 
+// Close flushes buffered async messages before disconnecting
 func (k *KafkaProducer) Close() {
+	if k.async != nil {
+		k.async.close()
+		logger.Log.Info("Kafka producer closed")
+	}
 	if k.producer != nil {
 		k.producer.Close()
 		logger.Log.Info("Kafka producer closed")
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"strconv"
+	"time"
+
+	"go1/pkg/metrics"
+
+	"github.com/IBM/sarama"
+)
+
+func observePublish(topic string, start time.Time, size int, err error) {
+	metrics.KafkaProducerPublishDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.KafkaProducerErrorsTotal.WithLabelValues(topic).Inc()
+		return
+	}
+	metrics.KafkaProducerBytesTotal.WithLabelValues(topic).Add(float64(size))
+}
+
+func observeHandled(topic string, start time.Time, err error) {
+	metrics.KafkaConsumerHandlerDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+	countHandled(topic, err)
+}
+
+func countHandled(topic string, err error) {
+	result := "processed"
+	if err != nil {
+		result = "failed"
+	}
+	metrics.KafkaConsumerMessagesTotal.WithLabelValues(topic, result).Inc()
+}
+
+// recordLag updates the partition's lag as of the message just received
+func (h *consumerGroupHandler) recordLag(claim sarama.ConsumerGroupClaim, message *sarama.ConsumerMessage) {
+	lag := claim.HighWaterMarkOffset() - message.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+	metrics.KafkaConsumerLag.WithLabelValues(h.consumer.opts.GroupID, claim.Topic(), strconv.Itoa(int(claim.Partition()))).Set(float64(lag))
+}
+
+// forgetLag drops the lag of partitions this member no longer owns after a rebalance
+func (h *consumerGroupHandler) forgetLag(claims map[string][]int32) {
+	for topic, partitions := range claims {
+		for _, partition := range partitions {
+			metrics.KafkaConsumerLag.DeleteLabelValues(h.consumer.opts.GroupID, topic, strconv.Itoa(int(partition)))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protobuf payload headers
+const (
+	HeaderProtoType     = "x-proto-type"     // Full name of the protobuf message
+	HeaderSchemaVersion = "x-schema-version" // Registered schema version the producer was compiled with
+
+	protobufContentType = "application/x-protobuf"
+)
+
+// UseSchemaRegistry makes PublishProto stamp and enforce registered schema versions
+func (k *KafkaProducer) UseSchemaRegistry(registry *SchemaRegistry) {
+	k.schemas = registry
+}
+
+// PublishProto publishes a protobuf-encoded message with content-type and type headers.
+// When a schema registry is configured, publishing a schema that is not registered fails.
+func (k *KafkaProducer) PublishProto(ctx context.Context, topic string, key string, msg proto.Message, headers ...sarama.RecordHeader) error {
+	value, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal protobuf message: %w", err)
+	}
+
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(headerContentType), Value: []byte(protobufContentType)},
+		sarama.RecordHeader{Key: []byte(HeaderProtoType), Value: []byte(msg.ProtoReflect().Descriptor().FullName())},
+	)
+	if k.schemas != nil {
+		version, err := k.schemas.VersionOf(msg)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, sarama.RecordHeader{Key: []byte(HeaderSchemaVersion), Value: []byte(strconv.Itoa(version))})
+	}
+
+	return k.PublishWithHeaders(ctx, topic, []byte(key), value, headers)
+}
+
+// HandleProto creates a MessageHandler that unmarshals protobuf payloads into T.
+// With a registry, the writer's schema version must be compatible with T's registered version;
+// incompatible messages return an error so they are retried or dead-lettered, not dropped.
+// Pass a nil registry to skip schema validation.
+func HandleProto[T proto.Message](registry *SchemaRegistry, typedHandler TypedMessageHandler[T]) MessageHandler {
+	typed := handleTyped(decodeProto[T], typedHandler)
+	return func(ctx context.Context, message *sarama.ConsumerMessage) error {
+		if err := checkProtoSchema[T](registry, message); err != nil {
+			return err
+		}
+		return typed(ctx, message)
+	}
+}
+
+func decodeProto[T proto.Message](message *sarama.ConsumerMessage) (T, error) {
+	var zero T
+	msg := zero.ProtoReflect().Type().New().Interface().(T)
+	err := proto.Unmarshal(message.Value, msg)
+	return msg, err
+}
+
+func checkProtoSchema[T proto.Message](registry *SchemaRegistry, message *sarama.ConsumerMessage) error {
+	var zero T
+	expected := string(zero.ProtoReflect().Descriptor().FullName())
+
+	var protoType, version string
+	for _, h := range message.Headers {
+		switch string(h.Key) {
+		case HeaderProtoType:
+			protoType = string(h.Value)
+		case HeaderSchemaVersion:
+			version = string(h.Value)
+		}
+	}
+	if protoType != "" && protoType != expected {
+		return Permanent(fmt.Errorf("unexpected protobuf type %s, want %s", protoType, expected))
+	}
+	if registry == nil {
+		return nil
+	}
+	if version == "" {
+		return fmt.Errorf("missing %s header for %s", HeaderSchemaVersion, expected)
+	}
+	writerVersion, err := strconv.Atoi(version)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid %s header %q", HeaderSchemaVersion, version))
+	}
+	return registry.CheckCompatible(zero, writerVersion)
+}
@@ -0,0 +1,163 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appConfig "go1/config"
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// ProvisionedTopic is a consumed topic together with the retry/DLQ topics derived from it
+type ProvisionedTopic struct {
+	Name       string
+	RetryTopic string // Empty when the topic has no retry
+	DLQTopic   string
+}
+
+// Provisioner creates missing topics with the configured partitions, replication and configs.
+// Existing topics are left as they are, except that retry topics must have at least as many
+// partitions as their base topic so that each base partition's retries stay spread the same way.
+type Provisioner struct {
+	admin sarama.ClusterAdmin
+	cfg   appConfig.KafkaProvisioningConfig
+}
+
+func NewProvisioner(kafkaConfig appConfig.KafkaConfig) (*Provisioner, error) {
+	config, err := newSaramaConfig(kafkaConfig)
+	if err != nil {
+		return nil, err
+	}
+	if config.Version, err = kafkaVersion(kafkaConfig.Consumer); err != nil {
+		return nil, err
+	}
+
+	admin, err := sarama.NewClusterAdmin(strings.Split(kafkaConfig.Brokers, ","), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka cluster admin: %w", err)
+	}
+	return &Provisioner{admin: admin, cfg: kafkaConfig.Provisioning}, nil
+}
+
+// Provision creates the missing base, retry and DLQ topics and validates retry partition counts
+func (p *Provisioner) Provision(topics []ProvisionedTopic) error {
+	existing, err := p.admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("failed to list kafka topics: %w", err)
+	}
+
+	var errs []error
+	for _, topic := range topics {
+		basePartitions, err := p.ensure(existing, topic.Name, 0)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if topic.RetryTopic != "" {
+			retryPartitions, err := p.ensure(existing, topic.RetryTopic, basePartitions)
+			if err != nil {
+				errs = append(errs, err)
+			} else if retryPartitions < basePartitions {
+				errs = append(errs, fmt.Errorf("retry topic %s has %d partitions, fewer than the %d of %s",
+					topic.RetryTopic, retryPartitions, basePartitions, topic.Name))
+			}
+		}
+
+		if _, err := p.ensure(existing, topic.DLQTopic, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ensure creates the topic if it does not exist, with at least minPartitions partitions,
+// and returns its partition count
+func (p *Provisioner) ensure(existing map[string]sarama.TopicDetail, name string, minPartitions int32) (int32, error) {
+	if detail, ok := existing[name]; ok {
+		return detail.NumPartitions, nil
+	}
+
+	spec := p.specFor(name)
+	detail := &sarama.TopicDetail{
+		NumPartitions:     max(int32(spec.Partitions), minPartitions),
+		ReplicationFactor: int16(spec.ReplicationFactor),
+		ConfigEntries:     make(map[string]*string),
+	}
+	if spec.RetentionMs > 0 {
+		retention := strconv.FormatInt(spec.RetentionMs, 10)
+		detail.ConfigEntries["retention.ms"] = &retention
+	}
+	if spec.CleanupPolicy != "" {
+		policy := spec.CleanupPolicy
+		detail.ConfigEntries["cleanup.policy"] = &policy
+	}
+
+	err := p.admin.CreateTopic(name, detail, false)
+	if errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		// Created concurrently, e.g. by another worker instance
+		return p.partitionCount(name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create topic %s: %w", name, err)
+	}
+
+	existing[name] = *detail
+	logger.Log.Info("Kafka topic created",
+		logger.Field{Key: "topic", Value: name},
+		logger.Field{Key: "partitions", Value: detail.NumPartitions},
+		logger.Field{Key: "replicationFactor", Value: detail.ReplicationFactor})
+	return detail.NumPartitions, nil
+}
+
+// specFor applies the topic's overrides on top of the defaults
+func (p *Provisioner) specFor(name string) appConfig.KafkaTopicSpec {
+	spec := p.cfg.Defaults
+	override, ok := p.cfg.Topics[name]
+	if !ok {
+		// Fallback for topics with dots (Viper issue): try replacing dots with underscores
+		override, ok = p.cfg.Topics[strings.ReplaceAll(name, ".", "_")]
+	}
+	if ok {
+		if override.Partitions > 0 {
+			spec.Partitions = override.Partitions
+		}
+		if override.ReplicationFactor > 0 {
+			spec.ReplicationFactor = override.ReplicationFactor
+		}
+		if override.RetentionMs > 0 {
+			spec.RetentionMs = override.RetentionMs
+		}
+		if override.CleanupPolicy != "" {
+			spec.CleanupPolicy = override.CleanupPolicy
+		}
+	}
+	if spec.Partitions <= 0 {
+		spec.Partitions = 1
+	}
+	if spec.ReplicationFactor <= 0 {
+		spec.ReplicationFactor = 1
+	}
+	return spec
+}
+
+func (p *Provisioner) partitionCount(name string) (int32, error) {
+	metadata, err := p.admin.DescribeTopics([]string{name})
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe topic %s: %w", name, err)
+	}
+	if len(metadata) == 0 || metadata[0].Err != sarama.ErrNoError {
+		return 0, fmt.Errorf("failed to describe topic %s", name)
+	}
+	return int32(len(metadata[0].Partitions)), nil
+}
+
+func (p *Provisioner) Close() {
+	if p.admin != nil {
+		p.admin.Close()
+	}
+}
@@ -0,0 +1,160 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Schema compatibility modes, from the point of view of a consumer (reader) receiving
+// a message produced with another registered version (writer)
+const (
+	CompatibilityBackward = "backward" // reader accepts writer versions <= its own (default)
+	CompatibilityForward  = "forward"  // reader accepts writer versions >= its own
+	CompatibilityFull     = "full"     // reader accepts any registered version
+	CompatibilityNone     = "none"     // reader accepts only its own version
+)
+
+type schemaVersion struct {
+	Version     int    `json:"version"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+type schemaSubject struct {
+	Compatibility string          `json:"compatibility"`
+	Versions      []schemaVersion `json:"versions"`
+}
+
+type schemaRegistryFile struct {
+	// Subjects are keyed by protobuf message full name, e.g. "go1.location.v1.DriverLocation"
+	Subjects map[string]schemaSubject `json:"subjects"`
+}
+
+// SchemaRegistry is a local, file-based registry of protobuf schema versions.
+// Producers stamp the registered version of the schema they were compiled with, and
+// consumers check that version against their own using the subject's compatibility mode.
+// A nil *SchemaRegistry disables validation.
+type SchemaRegistry struct {
+	subjects map[string]schemaSubject
+	// fingerprints caches descriptor fingerprints by message full name
+	fingerprints sync.Map
+}
+
+// LoadSchemaRegistry reads the registry file. Fingerprints are produced by SchemaFingerprint;
+// publishing an unregistered schema fails with an error that includes its fingerprint.
+func LoadSchemaRegistry(path string) (*SchemaRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema registry: %w", err)
+	}
+	var file schemaRegistryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid schema registry: %w", err)
+	}
+
+	for name, subject := range file.Subjects {
+		switch subject.Compatibility {
+		case "":
+			subject.Compatibility = CompatibilityBackward
+		case CompatibilityBackward, CompatibilityForward, CompatibilityFull, CompatibilityNone:
+		default:
+			return nil, fmt.Errorf("invalid compatibility %q for schema %s", subject.Compatibility, name)
+		}
+		seen := make(map[string]bool, len(subject.Versions))
+		for i, v := range subject.Versions {
+			if v.Version <= 0 || (i > 0 && v.Version <= subject.Versions[i-1].Version) {
+				return nil, fmt.Errorf("schema %s: versions must be positive and increasing", name)
+			}
+			if seen[v.Fingerprint] {
+				return nil, fmt.Errorf("schema %s: fingerprint registered twice", name)
+			}
+			seen[v.Fingerprint] = true
+		}
+		file.Subjects[name] = subject
+	}
+
+	return &SchemaRegistry{subjects: file.Subjects}, nil
+}
+
+// SchemaFingerprint identifies the schema of a message type by hashing its descriptor,
+// including nested types. Referenced top-level messages are versioned separately.
+func SchemaFingerprint(msg proto.Message) string {
+	desc := protodesc.ToDescriptorProto(msg.ProtoReflect().Descriptor())
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(desc)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// VersionOf returns the registered version of the schema msg was compiled with
+func (r *SchemaRegistry) VersionOf(msg proto.Message) (int, error) {
+	desc := msg.ProtoReflect().Descriptor()
+	subject, ok := r.subjects[string(desc.FullName())]
+	if !ok {
+		return 0, fmt.Errorf("schema %s is not registered", desc.FullName())
+	}
+	fingerprint := r.fingerprint(desc, msg)
+	for _, v := range subject.Versions {
+		if v.Fingerprint == fingerprint {
+			return v.Version, nil
+		}
+	}
+	return 0, fmt.Errorf("schema %s is not registered (fingerprint %s)", desc.FullName(), fingerprint)
+}
+
+// CheckCompatible validates that a reader compiled with msg's schema can consume a message
+// written with writerVersion
+func (r *SchemaRegistry) CheckCompatible(msg proto.Message, writerVersion int) error {
+	if r == nil {
+		return nil
+	}
+	readerVersion, err := r.VersionOf(msg)
+	if err != nil {
+		return err
+	}
+
+	name := msg.ProtoReflect().Descriptor().FullName()
+	subject := r.subjects[string(name)]
+	registered := false
+	for _, v := range subject.Versions {
+		if v.Version == writerVersion {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		return fmt.Errorf("schema %s: writer version %d is not registered", name, writerVersion)
+	}
+
+	compatible := false
+	switch subject.Compatibility {
+	case CompatibilityBackward:
+		compatible = writerVersion <= readerVersion
+	case CompatibilityForward:
+		compatible = writerVersion >= readerVersion
+	case CompatibilityFull:
+		compatible = true
+	case CompatibilityNone:
+		compatible = writerVersion == readerVersion
+	}
+	if !compatible {
+		return fmt.Errorf("schema %s: writer version %d is not %s compatible with reader version %d",
+			name, writerVersion, subject.Compatibility, readerVersion)
+	}
+	return nil
+}
+
+func (r *SchemaRegistry) fingerprint(desc protoreflect.MessageDescriptor, msg proto.Message) string {
+	if cached, ok := r.fingerprints.Load(desc.FullName()); ok {
+		return cached.(string)
+	}
+	fingerprint := SchemaFingerprint(msg)
+	r.fingerprints.Store(desc.FullName(), fingerprint)
+	return fingerprint
+}
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"context"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "go1/pkg/kafka"
+
+// producerHeaderCarrier adapts outgoing record headers to the OpenTelemetry propagator
+type producerHeaderCarrier struct {
+	headers *[]sarama.RecordHeader
+}
+
+func (c producerHeaderCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if strings.EqualFold(string(h.Key), key) {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the header, so republished messages carry the current span instead of the original one
+func (c producerHeaderCarrier) Set(key, value string) {
+	*c.headers = append(removeHeader(*c.headers, key), sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (c producerHeaderCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, h := range *c.headers {
+		keys[i] = string(h.Key)
+	}
+	return keys
+}
+
+// consumerHeaderCarrier adapts incoming record headers to the OpenTelemetry propagator
+type consumerHeaderCarrier []*sarama.RecordHeader
+
+func (c consumerHeaderCarrier) Get(key string) string {
+	return headerValue(c, key)
+}
+
+func (c consumerHeaderCarrier) Set(string, string) {}
+
+func (c consumerHeaderCarrier) Keys() []string {
+	keys := make([]string, len(c))
+	for i, h := range c {
+		keys[i] = string(h.Key)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = producerHeaderCarrier{}
+var _ propagation.TextMapCarrier = consumerHeaderCarrier{}
+
+// startProducerSpan starts a publish span and injects its context into headers
+func startProducerSpan(ctx context.Context, topic string, headers []sarama.RecordHeader) (trace.Span, []sarama.RecordHeader) {
+	// Copy so callers' header slices are never modified
+	headers = append(make([]sarama.RecordHeader, 0, len(headers)+2), headers...)
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingDestinationNameKey.String(topic),
+		),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, producerHeaderCarrier{headers: &headers})
+	return span, headers
+}
+
+func endProducerSpan(span trace.Span, partition int32, offset int64, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(
+			semconv.MessagingKafkaDestinationPartitionKey.Int(int(partition)),
+			attribute.Int64("messaging.kafka.message.offset", offset),
+		)
+	}
+	span.End()
+}
+
+// startConsumerSpan starts a process span as a child of the producer span found in the headers
+func startConsumerSpan(ctx context.Context, message *sarama.ConsumerMessage) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, consumerHeaderCarrier(message.Headers))
+	return otel.Tracer(tracerName).Start(ctx, message.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingSourceNameKey.String(message.Topic),
+			semconv.MessagingOperationProcess,
+			semconv.MessagingKafkaSourcePartitionKey.Int(int(message.Partition)),
+			attribute.Int64("messaging.kafka.message.offset", message.Offset),
+			attribute.Int("messaging.kafka.attempt", getAttempts(message.Headers)),
+		),
+	)
+}
+
+// startBatchSpan starts a process span for a batch, linked to the producer span of every message
+func startBatchSpan(ctx context.Context, topic string, partition int32, messages []*sarama.ConsumerMessage) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(messages))
+	for _, m := range messages {
+		producerCtx := otel.GetTextMapPropagator().Extract(context.Background(), consumerHeaderCarrier(m.Headers))
+		if sc := trace.SpanContextFromContext(producerCtx); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	return otel.Tracer(tracerName).Start(ctx, topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingSourceNameKey.String(topic),
+			semconv.MessagingOperationProcess,
+			semconv.MessagingKafkaSourcePartitionKey.Int(int(partition)),
+			attribute.Int("messaging.batch.message_count", len(messages)),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,140 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	appConfig "go1/config"
+	"go1/pkg/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// ErrNotTransactional is returned when starting a transaction on a non-transactional producer
+var ErrNotTransactional = errors.New("kafka producer is not transactional")
+
+// txnProducer is the transaction API shared by sarama's sync and async producers
+type txnProducer interface {
+	IsTransactional() bool
+	BeginTxn() error
+	CommitTxn() error
+	AbortTxn() error
+	AddMessageToTxn(msg *sarama.ConsumerMessage, groupId string, metadata *string) error
+}
+
+// NewTransactionalProducer connects a producer whose messages are only published through
+// transactions. transactionalID must be stable for one producer instance and unique across
+// instances, so that a restarted instance fences off its previous incarnation.
+func NewTransactionalProducer(kafkaConfig appConfig.KafkaConfig, transactionalID string) (*KafkaProducer, error) {
+	if transactionalID == "" {
+		return nil, fmt.Errorf("transactional producer requires a transactional id")
+	}
+	return newProducer(kafkaConfig, transactionalID)
+}
+
+// consumerTransactionalID derives the id of a consumer's retry/DLQ producer from the configured
+// prefix, the consumer group and the host, so every worker instance gets its own
+func consumerTransactionalID(prefix, groupID string) string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return prefix + "-" + groupID + "-" + host
+}
+
+func (k *KafkaProducer) txn() txnProducer {
+	if k.async != nil {
+		return k.async.producer
+	}
+	return k.producer
+}
+
+// IsTransactional reports whether the producer publishes through transactions
+func (k *KafkaProducer) IsTransactional() bool {
+	return k.txn().IsTransactional()
+}
+
+// Transaction groups published messages and consumed offsets that become visible together
+// on Commit or not at all on Abort. Only one transaction per producer is open at a time.
+type Transaction struct {
+	producer *KafkaProducer
+	done     bool
+}
+
+// BeginTransaction starts a transaction, waiting for the previous one to finish
+func (k *KafkaProducer) BeginTransaction() (*Transaction, error) {
+	if !k.IsTransactional() {
+		return nil, ErrNotTransactional
+	}
+
+	k.txnMu.Lock()
+	if err := k.txn().BeginTxn(); err != nil {
+		k.txnMu.Unlock()
+		return nil, fmt.Errorf("failed to begin kafka transaction: %w", err)
+	}
+	return &Transaction{producer: k}, nil
+}
+
+// Publish sends a message as part of the transaction and waits until the broker has it
+func (tx *Transaction) Publish(ctx context.Context, topic string, key, value []byte, headers []sarama.RecordHeader) error {
+	return tx.producer.PublishWithHeaders(ctx, topic, key, value, headers)
+}
+
+// AddOffsets commits the consumed messages' offsets for groupID with the transaction, so
+// consume-transform-produce either both publishes and advances the group, or neither
+func (tx *Transaction) AddOffsets(groupID string, messages ...*sarama.ConsumerMessage) error {
+	for _, message := range messages {
+		if err := tx.producer.txn().AddMessageToTxn(message, groupID, nil); err != nil {
+			return fmt.Errorf("failed to add offset to kafka transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// Commit makes the transaction's messages and offsets visible
+func (tx *Transaction) Commit() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+	defer tx.producer.txnMu.Unlock()
+
+	if err := tx.producer.txn().CommitTxn(); err != nil {
+		// The transaction cannot complete; abort so the producer can start the next one
+		if abortErr := tx.producer.txn().AbortTxn(); abortErr != nil {
+			logger.Log.Error("Failed to abort kafka transaction", logger.Field{Key: "error", Value: abortErr})
+		}
+		return fmt.Errorf("failed to commit kafka transaction: %w", err)
+	}
+	return nil
+}
+
+// Abort discards the transaction's messages and offsets. Safe to call after Commit.
+func (tx *Transaction) Abort() error {
+	if tx.done {
+		return nil
+	}
+	tx.done = true
+	defer tx.producer.txnMu.Unlock()
+
+	if err := tx.producer.txn().AbortTxn(); err != nil {
+		return fmt.Errorf("failed to abort kafka transaction: %w", err)
+	}
+	return nil
+}
+
+// Transact runs fn in a transaction, committing if it returns nil and aborting otherwise
+func (k *KafkaProducer) Transact(fn func(tx *Transaction) error) error {
+	tx, err := k.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Abort()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// KafkaConsumerMessagesTotal tracks consumed messages by handler result (processed, failed)
+	KafkaConsumerMessagesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_messages_total",
+			Help: "Total number of Kafka messages handled by the consumer",
+		},
+		[]string{"topic", "result"},
+	)
+
+	// KafkaConsumerHandlerDuration tracks handler latency; batch topics observe once per batch
+	KafkaConsumerHandlerDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_consumer_handler_duration_seconds",
+			Help:    "Kafka message handler duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"topic"},
+	)
+
+	// KafkaConsumerRetriesTotal tracks messages republished to the retry topic, by base topic
+	KafkaConsumerRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_retries_total",
+			Help: "Total number of Kafka messages sent to a retry topic",
+		},
+		[]string{"topic"},
+	)
+
+	// KafkaConsumerDLQTotal tracks messages dead-lettered, by base topic
+	KafkaConsumerDLQTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_dlq_total",
+			Help: "Total number of Kafka messages sent to a DLQ topic",
+		},
+		[]string{"topic"},
+	)
+
+	// KafkaConsumerLag tracks how far the consumer is behind the partition's high water mark
+	KafkaConsumerLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_consumer_lag",
+			Help: "Number of messages between the last consumed offset and the partition high water mark",
+		},
+		[]string{"group", "topic", "partition"},
+	)
+
+	// KafkaConsumerRebalancesTotal tracks consumer group sessions started after a rebalance
+	KafkaConsumerRebalancesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_rebalances_total",
+			Help: "Total number of Kafka consumer group rebalances",
+		},
+		[]string{"group"},
+	)
+
+	// KafkaProducerPublishDuration tracks synchronous publish latency
+	KafkaProducerPublishDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_producer_publish_duration_seconds",
+			Help:    "Kafka publish duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"topic"},
+	)
+
+	// KafkaProducerErrorsTotal tracks failed publishes
+	KafkaProducerErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_producer_errors_total",
+			Help: "Total number of failed Kafka publishes",
+		},
+		[]string{"topic"},
+	)
+
+	// KafkaProducerBytesTotal tracks key and value bytes successfully published
+	KafkaProducerBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_producer_bytes_total",
+			Help: "Total number of Kafka key and value bytes published",
+		},
+		[]string{"topic"},
+	)
+)
@@ -13,7 +13,7 @@ var (
 			Help:    "HTTP request duration in seconds",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "path", "status", "partner"},
 	)
 
 	// HTTPRequestsTotal tracks total HTTP requests
@@ -22,7 +22,7 @@ var (
 			Name: "http_requests_total",
 			Help: "Total number of HTTP requests",
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "path", "status", "partner"},
 	)
 
 	// ActiveRequests tracks concurrent users (active requests)
@@ -32,4 +32,40 @@ var (
 			Help: "Number of active HTTP requests (CCU)",
 		},
 	)
+
+	// OrdersCreatedTotal tracks orders created per partner and service type
+	OrdersCreatedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "orders_created_total",
+			Help: "Total number of orders created",
+		},
+		[]string{"partner", "service_type"},
+	)
+
+	// CacheRequestsTotal tracks cache lookups by result (hit, miss, error)
+	CacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_requests_total",
+			Help: "Total number of cache lookups",
+		},
+		[]string{"cache", "result"},
+	)
+
+	// CacheInvalidationsTotal tracks cache entries invalidated
+	CacheInvalidationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_total",
+			Help: "Total number of cache invalidations",
+		},
+		[]string{"cache", "source"},
+	)
+
+	// RateLimitHitsTotal tracks requests rejected by the rate limiter
+	RateLimitHitsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_rate_limit_hits_total",
+			Help: "Total number of HTTP requests rejected by the rate limiter",
+		},
+		[]string{"method", "path", "role"},
+	)
 )
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"go1/pkg/logger"
+	"go1/pkg/redis"
+	"go1/pkg/request"
+	"go1/pkg/response"
+	"go1/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	idempotencyKeyPrefix    = "idempotency:"
+	idempotencyMaxKeyLength = 255
+
+	idempotencyStateProcessing = "processing"
+	idempotencyStateCompleted  = "completed"
+
+	// idempotencyFinishTimeout bounds the write that completes or releases a key
+	idempotencyFinishTimeout = 2 * time.Second
+)
+
+// IdempotencyOptions configures IdempotencyMiddleware.
+type IdempotencyOptions struct {
+	// TTL is how long a completed response is kept and replayed.
+	TTL time.Duration
+	// LockTimeout is how long an in-flight request holds the key before another attempt may take over.
+	LockTimeout time.Duration
+}
+
+// idempotencyRecord is the value stored in Redis for each Idempotency-Key.
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	RequestHash string `json:"request_hash"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// IdempotencyMiddleware makes unsafe requests carrying an Idempotency-Key header safe to retry.
+// The first request takes a lock on the key; repeated requests with the same key and body receive
+// the original response, a different body is rejected with 422, and a duplicate arriving while
+// the first is still in flight is rejected with 409. Requests without the header pass through.
+// Keys are scoped per user and route. If Redis is unavailable the request is processed normally.
+func IdempotencyMiddleware(rd *redis.RedisClient, opts IdempotencyOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(string(utils.IdempotencyKeyHeader))
+		if key == "" || !isUnsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeyLength {
+			response.Error(c, http.StatusBadRequest, "Idempotency-Key must not exceed 255 characters")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "failed to read request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		redisKey := idempotencyRedisKey(c, key)
+		requestHash := hashRequest(c.Request.Method, c.FullPath(), body)
+
+		lock, _ := json.Marshal(idempotencyRecord{State: idempotencyStateProcessing, RequestHash: requestHash})
+		acquired, err := rd.Client.SetNX(ctx, redisKey, lock, opts.LockTimeout).Result()
+		if err != nil {
+			logger.Log.Warn("Idempotency store unavailable, processing request without it",
+				logger.Field{Key: "key", Value: key},
+				logger.Field{Key: "error", Value: err})
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, rd, redisKey, requestHash)
+			return
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// The client may have gone away by now; finish the key regardless so it is not left locked
+		finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyFinishTimeout)
+		defer cancel()
+
+		// Server errors are not cached so the client can retry with the same key
+		if writer.Status() >= http.StatusInternalServerError {
+			if err := rd.Client.Del(finishCtx, redisKey).Err(); err != nil {
+				logger.Log.Warn("Failed to release idempotency key",
+					logger.Field{Key: "key", Value: key},
+					logger.Field{Key: "error", Value: err})
+			}
+			return
+		}
+
+		record, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			RequestHash: requestHash,
+			StatusCode:  writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err := rd.Client.Set(finishCtx, redisKey, record, opts.TTL).Err(); err != nil {
+			logger.Log.Warn("Failed to store idempotent response",
+				logger.Field{Key: "key", Value: key},
+				logger.Field{Key: "error", Value: err})
+		}
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, rd *redis.RedisClient, redisKey, requestHash string) {
+	defer c.Abort()
+
+	raw, err := rd.Client.Get(c.Request.Context(), redisKey).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		// The previous holder released the key between SETNX and GET (e.g. it failed with 5xx)
+		response.Error(c, http.StatusConflict, "request with this Idempotency-Key is being processed, retry later")
+		return
+	}
+	if err != nil {
+		response.Error(c, http.StatusServiceUnavailable, "idempotency store unavailable")
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		response.Error(c, http.StatusInternalServerError, "corrupted idempotency record")
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		response.Error(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+		return
+	}
+
+	if record.State != idempotencyStateCompleted {
+		response.Error(c, http.StatusConflict, "request with this Idempotency-Key is being processed, retry later")
+		return
+	}
+
+	c.Header(string(utils.IdempotentReplayedHeader), "true")
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+}
+
+func idempotencyRedisKey(c *gin.Context, key string) string {
+	userID := ""
+	if user, ok := request.UserFromContext(c.Request.Context()); ok {
+		userID = user.UserID
+	}
+	return idempotencyKeyPrefix + userID + ":" + c.Request.Method + ":" + c.FullPath() + ":" + key
+}
+
+func hashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte(path))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// bodyCaptureWriter records the response body while writing it to the client
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
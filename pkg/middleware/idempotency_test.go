@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go1/pkg/logger"
+	"go1/pkg/redis"
+	"go1/pkg/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.RedisClient) {
+	t.Helper()
+	logger.SetLogger(logger.NewZapLogger("production"))
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, &redis.RedisClient{Client: client}
+}
+
+// idempotentRouter serves POST /orders behind IdempotencyMiddleware; handle decides each response
+func idempotentRouter(rd *redis.RedisClient, handle func(c *gin.Context)) *gin.Engine {
+	router := gin.New()
+	router.Use(IdempotencyMiddleware(rd, IdempotencyOptions{TTL: time.Hour, LockTimeout: time.Minute}))
+	router.POST("/orders", handle)
+	return router
+}
+
+func postOrder(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(string(utils.IdempotencyKeyHeader), key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	_, rd := newTestRedis(t)
+	var calls atomic.Int32
+	router := idempotentRouter(rd, func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+
+	first := postOrder(router, "k1", `{"a":1}`)
+	second := postOrder(router, "k1", `{"a":1}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replay content type = %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+	if second.Header().Get(string(utils.IdempotentReplayedHeader)) != "true" {
+		t.Error("replay is missing the Idempotent-Replayed header")
+	}
+	if first.Header().Get(string(utils.IdempotentReplayedHeader)) != "" {
+		t.Error("first response has the Idempotent-Replayed header")
+	}
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	_, rd := newTestRedis(t)
+	var calls atomic.Int32
+	router := idempotentRouter(rd, func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusCreated)
+	})
+
+	postOrder(router, "k1", `{"a":1}`)
+	if rec := postOrder(router, "k1", `{"a":2}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", rec.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyRejectsDuplicateInFlight(t *testing.T) {
+	_, rd := newTestRedis(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router := idempotentRouter(rd, func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postOrder(router, "k1", `{"a":1}`) }()
+	<-started
+
+	if rec := postOrder(router, "k1", `{"a":1}`); rec.Code != http.StatusConflict {
+		t.Errorf("in-flight duplicate status = %d, want 409", rec.Code)
+	}
+	close(release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Errorf("first request status = %d, want 201", rec.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	var calls atomic.Int32
+	router := idempotentRouter(rd, func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	if rec := postOrder(router, "k1", `{"a":1}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want 500", rec.Code)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Fatalf("keys after 5xx = %v, want none", keys)
+	}
+	if rec := postOrder(router, "k1", `{"a":1}`); rec.Code != http.StatusCreated {
+		t.Errorf("retry status = %d, want 201", rec.Code)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler called %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotencyPassesThroughWithoutKey(t *testing.T) {
+	mr, rd := newTestRedis(t)
+	var calls atomic.Int32
+	router := idempotentRouter(rd, func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusCreated)
+	})
+
+	postOrder(router, "", `{"a":1}`)
+	postOrder(router, "", `{"a":1}`)
+
+	if calls.Load() != 2 {
+		t.Errorf("handler called %d times, want 2", calls.Load())
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys = %v, want none", keys)
+	}
+}
+
+func TestIdempotencyProcessesRequestWhenRedisIsDown(t *testing.T) {
+	mr, _ := newTestRedis(t)
+	addr := mr.Addr()
+	mr.Close()
+	rd := &redis.RedisClient{Client: goredis.NewClient(&goredis.Options{Addr: addr, MaxRetries: -1})}
+	defer rd.Close()
+	var calls atomic.Int32
+	router := idempotentRouter(rd, func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusCreated)
+	})
+
+	if rec := postOrder(router, "k1", `{"a":1}`); rec.Code != http.StatusCreated || calls.Load() != 1 {
+		t.Errorf("status = %d after %d calls, want 201 after 1", rec.Code, calls.Load())
+	}
+}
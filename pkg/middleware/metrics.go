@@ -4,13 +4,18 @@ import (
 	"strconv"
 	"time"
 
+	"go1/config"
 	"go1/pkg/metrics"
+	"go1/pkg/request"
 
 	"github.com/gin-gonic/gin"
 )
 
+// unknownPartnerLabel replaces partner IDs that are not configured, keeping the label bounded
+const unknownPartnerLabel = "unknown"
+
 // MetricsMiddleware records Prometheus metrics for each request
-func MetricsMiddleware() gin.HandlerFunc {
+func MetricsMiddleware(partners map[string]config.PartnerConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
@@ -28,17 +33,33 @@ func MetricsMiddleware() gin.HandlerFunc {
 		if path == "" {
 			path = c.Request.URL.Path
 		}
+		partner := partnerLabel(c, partners)
 
 		metrics.HTTPRequestDuration.WithLabelValues(
 			c.Request.Method,
 			path,
 			status,
+			partner,
 		).Observe(duration)
 
 		metrics.HTTPRequestsTotal.WithLabelValues(
 			c.Request.Method,
 			path,
 			status,
+			partner,
 		).Inc()
 	}
 }
+
+// partnerLabel returns the caller's partner ID when it is configured. The user is set by the
+// auth middleware further down the chain, so it is read after the request has been handled.
+func partnerLabel(c *gin.Context, partners map[string]config.PartnerConfig) string {
+	user, ok := request.UserFromContext(c.Request.Context())
+	if !ok || user.PartnerID == "" {
+		return ""
+	}
+	if _, ok := partners[user.PartnerID]; !ok {
+		return unknownPartnerLabel
+	}
+	return user.PartnerID
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go1/config"
+	"go1/pkg/metrics"
+	"go1/pkg/request"
+	"go1/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func rateLimitedRouter(t *testing.T, cfg config.RateLimitConfig) (*gin.Engine, func() int) {
+	mr, rd := newTestRedis(t)
+	router := gin.New()
+	// Stands in for the auth middleware
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("Test-User"); id != "" {
+			user := &request.UserContext{UserID: id, Role: utils.UserRoleCustomer}
+			c.Request = c.Request.WithContext(request.WithUser(c.Request.Context(), user))
+		}
+		c.Next()
+	})
+	router.Use(RateLimitMiddleware(rd, cfg))
+	router.GET("/orders/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	// Number of requests recorded in all sliding windows
+	recorded := func() int {
+		total := 0
+		for _, key := range mr.Keys() {
+			members, _ := mr.ZMembers(key)
+			total += len(members)
+		}
+		return total
+	}
+	return router, recorded
+}
+
+func send(router http.Handler, method, path, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if user != "" {
+		req.Header.Set("Test-User", user)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitRejectsOverLimit(t *testing.T) {
+	router, recorded := rateLimitedRouter(t, config.RateLimitConfig{
+		Default: config.RateLimitRule{Requests: 2, WindowSeconds: 60},
+	})
+	hits := metrics.RateLimitHitsTotal.WithLabelValues(http.MethodGet, "/orders/:id", string(utils.UserRoleCustomer))
+	hitsBefore := testutil.ToFloat64(hits)
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		rec := send(router, http.MethodGet, "/orders/o1", "c1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d X-RateLimit-Limit = %q, want 2", i+1, got)
+		}
+		if got := rec.Header().Get("X-RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("request %d X-RateLimit-Remaining = %q, want %s", i+1, got, wantRemaining)
+		}
+		if got := recorded(); got != i+1 {
+			t.Errorf("after request %d the window holds %d requests, want %d", i+1, got, i+1)
+		}
+	}
+
+	// Another order ID hits the same route pattern
+	rec := send(router, http.MethodGet, "/orders/o2", "c1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After = %q, want 1..60 seconds", rec.Header().Get("Retry-After"))
+	}
+	if got := rec.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, want 0", got)
+	}
+	if got := recorded(); got != 2 {
+		t.Errorf("rejected request was recorded: window holds %d requests, want 2", got)
+	}
+	if got := testutil.ToFloat64(hits) - hitsBefore; got != 1 {
+		t.Errorf("rate limit hits increased by %v, want 1", got)
+	}
+
+	// Other callers have their own window
+	if rec := send(router, http.MethodGet, "/orders/o1", "c2"); rec.Code != http.StatusOK {
+		t.Errorf("other user status = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimitRouteRules(t *testing.T) {
+	router, _ := rateLimitedRouter(t, config.RateLimitConfig{
+		Default: config.RateLimitRule{Requests: 5, WindowSeconds: 60},
+		Routes: []config.RouteRateLimitConfig{
+			{Method: "post", Path: "/orders", Requests: 1, WindowSeconds: 60},
+			{Method: http.MethodGet, Path: "/orders/:id", Requests: 0, WindowSeconds: 60},
+		},
+	})
+
+	if rec := send(router, http.MethodPost, "/orders", "c1"); rec.Code != http.StatusCreated || rec.Header().Get("X-RateLimit-Limit") != "1" {
+		t.Fatalf("first create = %d with limit %q, want 201 with limit 1", rec.Code, rec.Header().Get("X-RateLimit-Limit"))
+	}
+	if rec := send(router, http.MethodPost, "/orders", "c1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("second create status = %d, want 429", rec.Code)
+	}
+
+	// A zero limit disables limiting for the route
+	for range 10 {
+		rec := send(router, http.MethodGet, "/orders/o1", "c1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unlimited route status = %d, want 200", rec.Code)
+		}
+		if rec.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatal("unlimited route sent X-RateLimit-Limit")
+		}
+	}
+}
+
+func TestRateLimitFallsBackToClientIP(t *testing.T) {
+	router, _ := rateLimitedRouter(t, config.RateLimitConfig{
+		Default: config.RateLimitRule{Requests: 1, WindowSeconds: 60},
+	})
+
+	send(router, http.MethodGet, "/orders/o1", "")
+	if rec := send(router, http.MethodGet, "/orders/o1", ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("anonymous status = %d, want 429", rec.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Message is a domain event waiting in the outbox table to be published
+type Message struct {
+	ID            string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	EventVersion  int
+	Payload       any
+}
+
+// NewMessage creates an outbox message with a fresh event ID
+func NewMessage(aggregateType, aggregateID, eventType string, eventVersion int, payload any) Message {
+	return Message{
+		ID:            ulid.Make().String(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		EventVersion:  eventVersion,
+		Payload:       payload,
+	}
+}
+
+// Insert writes messages inside tx so they are committed atomically with the state change.
+// The trace context of ctx is stored so the relay can continue the trace when publishing.
+func Insert(ctx context.Context, tx pgx.Tx, messages ...Message) error {
+	query := `INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, event_version, payload, traceparent, tracestate)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	for _, m := range messages {
+		payload, err := json.Marshal(m.Payload)
+		if err != nil {
+			return fmt.Errorf("outbox.Insert: failed to marshal payload: %w", err)
+		}
+		if _, err := tx.Exec(ctx, query, m.ID, m.AggregateType, m.AggregateID, m.EventType, m.EventVersion, payload,
+			carrier.Get("traceparent"), carrier.Get("tracestate")); err != nil {
+			return fmt.Errorf("outbox.Insert: %w", err)
+		}
+	}
+	return nil
+}
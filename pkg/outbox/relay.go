@@ -0,0 +1,233 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go1/pkg/kafka"
+	"go1/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// relayLockID is the Postgres advisory lock that keeps a single relay active at a time,
+// so events are published in outbox order across all worker replicas.
+const relayLockID = 7260330
+
+// RelayOptions configures the outbox relay
+type RelayOptions struct {
+	// Topics maps aggregate types to the Kafka topic their events are published to
+	Topics map[string]string
+	// PollInterval is the wait between polls when the outbox is drained. Defaults to 1s.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of events published per poll. Defaults to 100.
+	BatchSize int
+	// Retention is how long published events are kept before being deleted. Zero keeps them.
+	Retention time.Duration
+}
+
+type outboxRow struct {
+	seq           int64
+	id            string
+	aggregateType string
+	aggregateID   string
+	eventType     string
+	eventVersion  int
+	payload       []byte
+	createdAt     time.Time
+	traceParent   string
+	traceState    string
+}
+
+// Relay publishes outbox events to Kafka with at-least-once delivery. Events are keyed by
+// aggregate ID so they land on the same partition, and an aggregate has at most one event in
+// flight, so a failed event is never overtaken by a later event of the same aggregate.
+// Events of different aggregates are pipelined when the producer is async.
+type Relay struct {
+	db       *pgxpool.Pool
+	producer *kafka.KafkaProducer
+	opts     RelayOptions
+}
+
+// NewRelay requires an async producer to be idempotent, so its retries cannot reorder events
+func NewRelay(db *pgxpool.Pool, producer *kafka.KafkaProducer, opts RelayOptions) (*Relay, error) {
+	if producer.IsAsync() && !producer.IsIdempotent() {
+		return nil, fmt.Errorf("outbox relay requires kafka.producer.idempotent with an async producer")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	return &Relay{db: db, producer: producer, opts: opts}, nil
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) error {
+	logger.Log.Info("Outbox relay started",
+		logger.Field{Key: "pollInterval", Value: r.opts.PollInterval},
+		logger.Field{Key: "batchSize", Value: r.opts.BatchSize})
+
+	lastCleanup := time.Time{}
+	for {
+		published, err := r.relayBatch(ctx)
+		if err != nil {
+			logger.Log.Error("Outbox relay batch failed", logger.Field{Key: "error", Value: err})
+		}
+
+		if r.opts.Retention > 0 && time.Since(lastCleanup) > time.Hour {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		// Keep draining while batches are full
+		if err == nil && published == r.opts.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Outbox relay stopped")
+			return ctx.Err()
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, relayLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to acquire relay lock: %w", err)
+	}
+	if !locked {
+		// Another replica is relaying
+		return 0, nil
+	}
+
+	rows, err := r.fetchUnpublished(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Events that were not delivered are published again on the next poll
+	published, publishErr := r.publishAll(ctx, rows)
+
+	if len(published) > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE outbox SET published_at = NOW() WHERE id = ANY($1)`, published); err != nil {
+			return 0, fmt.Errorf("failed to mark events published: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(published), publishErr
+}
+
+func (r *Relay) fetchUnpublished(ctx context.Context, tx pgx.Tx) ([]outboxRow, error) {
+	query := `SELECT seq, id, aggregate_type, aggregate_id, event_type, event_version, payload, created_at, traceparent, tracestate
+	FROM outbox WHERE published_at IS NULL ORDER BY seq LIMIT $1`
+
+	rows, err := tx.Query(ctx, query, r.opts.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var result []outboxRow
+	for rows.Next() {
+		var row outboxRow
+		if err := rows.Scan(&row.seq, &row.id, &row.aggregateType, &row.aggregateID, &row.eventType, &row.eventVersion, &row.payload, &row.createdAt, &row.traceParent, &row.traceState); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// publishAll queues the rows in outbox order and returns the IDs of those delivered. The next
+// event of an aggregate is only queued once its previous one was delivered; otherwise an event
+// behind a failed one could be written first. Queueing stops at the first known failure.
+func (r *Relay) publishAll(ctx context.Context, rows []outboxRow) ([]string, error) {
+	lastOf := make(map[string]*kafka.Delivery) // Aggregate -> its latest queued event
+	deliveries := make([]*kafka.Delivery, 0, len(rows))
+	var publishErr error
+	for _, row := range rows {
+		aggregate := row.aggregateType + "/" + row.aggregateID
+		if previous, ok := lastOf[aggregate]; ok {
+			if _, err := previous.Wait(ctx); err != nil {
+				publishErr = err
+				break
+			}
+		}
+		delivery, err := r.publish(ctx, row)
+		if err != nil {
+			publishErr = err
+			break
+		}
+		deliveries = append(deliveries, delivery)
+		lastOf[aggregate] = delivery
+	}
+
+	published := make([]string, 0, len(deliveries))
+	for i, delivery := range deliveries {
+		if _, err := delivery.Wait(ctx); err != nil {
+			if publishErr == nil {
+				publishErr = err
+			}
+			continue
+		}
+		published = append(published, rows[i].id)
+	}
+	return published, publishErr
+}
+
+func (r *Relay) publish(ctx context.Context, row outboxRow) (*kafka.Delivery, error) {
+	topic, ok := r.opts.Topics[row.aggregateType]
+	if !ok {
+		return nil, fmt.Errorf("no topic configured for aggregate type %q", row.aggregateType)
+	}
+
+	event := kafka.Event[json.RawMessage]{
+		ID:          row.id,
+		Type:        row.eventType,
+		Version:     row.eventVersion,
+		OccurredAt:  row.createdAt.UTC(),
+		AggregateID: row.aggregateID,
+		TraceParent: row.traceParent,
+		TraceState:  row.traceState,
+		Data:        row.payload,
+	}
+
+	// Publish under the trace of the request that wrote the event
+	if row.traceParent != "" {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{
+			"traceparent": row.traceParent,
+			"tracestate":  row.traceState,
+		})
+	}
+	return kafka.PublishEventAsync(ctx, r.producer, topic, event)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE published_at < $1`, time.Now().Add(-r.opts.Retention))
+	if err != nil {
+		logger.Log.Warn("Failed to clean up outbox", logger.Field{Key: "error", Value: err})
+		return
+	}
+	if tag.RowsAffected() > 0 {
+		logger.Log.Info("Outbox cleaned up", logger.Field{Key: "deleted", Value: tag.RowsAffected()})
+	}
+}
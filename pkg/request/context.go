@@ -24,3 +24,14 @@ func UserFromContext(ctx context.Context) (*UserContext, bool) {
 	user, ok := ctx.Value(userContextKey).(*UserContext)
 	return user, ok
 }
+
+// PartnerScope returns the partner whose orders the caller is confined to. Internal callers
+// (workflows, consumers) carry no user and are not scoped; an API caller without a partner ID
+// is scoped to first-party orders.
+func PartnerScope(ctx context.Context) (partnerID string, scoped bool) {
+	user, ok := UserFromContext(ctx)
+	if !ok || user == nil {
+		return "", false
+	}
+	return user.PartnerID, true
+}
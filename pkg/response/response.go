@@ -1,6 +1,7 @@
 package response
 
 import (
+	"errors"
 	"net/http"
 
 	"go1/pkg/apperrors"
@@ -63,7 +64,8 @@ func HandleBindingError(c *gin.Context, err error) {
 }
 
 func HandleError(c *gin.Context, err error) {
-	if appErr, ok := err.(*apperrors.AppError); ok {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
 		c.JSON(appErr.Status, Response{
 			Success: false,
 			Message: appErr.Message,
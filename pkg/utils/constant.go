@@ -15,6 +15,9 @@ const (
 	XUserAudienceHeader MyHeader = "X-User-Audience"
 	XUserPlatformHeader MyHeader = "X-User-Platform"
 	XPartnerIDHeader    MyHeader = "X-Partner-Id"
+
+	IdempotencyKeyHeader     MyHeader = "Idempotency-Key"
+	IdempotentReplayedHeader MyHeader = "Idempotent-Replayed"
 )
 
 type ServiceType string
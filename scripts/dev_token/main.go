@@ -0,0 +1,63 @@
+// dev_token mints a bearer token signed with the local dev key (config/jwt.dev.pem), which
+// config/jwks.dev.json lets the API verify. Never deploy that key set.
+//
+//	go run ./scripts/dev_token -sub cust_123 -role user_app
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func main() {
+	keyFile := flag.String("key", "config/jwt.dev.pem", "PEM-encoded PKCS#8 EC private key")
+	kid := flag.String("kid", "go1-dev", "Key ID of the key in config/jwks.dev.json")
+	sub := flag.String("sub", "cust_123", "User ID")
+	role := flag.String("role", "user_app", "Role: user_app, driver_app or admin_portal")
+	platform := flag.String("platform", "", "Platform claim")
+	partner := flag.String("partner", "", "Partner ID claim")
+	ttl := flag.Duration("ttl", 24*time.Hour, "Token lifetime")
+	flag.Parse()
+
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		log.Fatalf("Failed to read key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		log.Fatalf("No PEM block in %s", *keyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		log.Fatalf("Failed to parse key: %v", err)
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":  *sub,
+		"role": *role,
+		"iat":  now.Unix(),
+		"exp":  now.Add(*ttl).Unix(),
+	}
+	if *platform != "" {
+		claims["platform"] = *platform
+	}
+	if *partner != "" {
+		claims["partner_id"] = *partner
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = *kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+	fmt.Println(signed)
+}
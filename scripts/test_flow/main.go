@@ -2,19 +2,24 @@ package main
 
 import (
 	"bytes"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
 	apiURL       = "http://localhost:8080"
-	kafkaBrokers = "localhost:9099" // Use external port for localhost
+	kafkaBrokers = "localhost:9099"     // Use external port for localhost
+	devKeyFile   = "config/jwt.dev.pem" // Signs tokens the API verifies with config/jwks.dev.json
 	// Topics from builder.go
 	dispatchTopic = "dispatch-events"
 	shipmentTopic = "shipment-events"
@@ -51,6 +56,17 @@ type ShipmentEvent struct {
 	Status  string `json:"status"`
 }
 
+// Event mirrors the envelope in pkg/kafka/event.go
+type Event struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	Version     int         `json:"version"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+	AggregateID string      `json:"aggregate_id"`
+	Producer    string      `json:"producer"`
+	Data        interface{} `json:"data"`
+}
+
 func main() {
 	// 1. Initialize Kafka Producer
 	producer, err := newKafkaProducer()
@@ -76,7 +92,7 @@ func main() {
 		OrderID:        orderID,
 		DispatchStatus: "success",
 	}
-	if err := publishMessage(producer, dispatchTopic, orderID, dispatchEvent); err != nil {
+	if err := publishMessage(producer, dispatchTopic, orderID, "OrderDispatched", dispatchEvent); err != nil {
 		log.Fatalf("❌ Failed to publish dispatch event: %v", err)
 	}
 	fmt.Println("✅ Dispatched event published to Kafka!")
@@ -89,7 +105,7 @@ func main() {
 		OrderID: orderID,
 		Status:  "DELIVERED",
 	}
-	if err := publishMessage(producer, shipmentTopic, orderID, deliveryEvent); err != nil {
+	if err := publishMessage(producer, shipmentTopic, orderID, "ShipmentStatusChanged", deliveryEvent); err != nil {
 		log.Fatalf("❌ Failed to publish shipment event: %v", err)
 	}
 	fmt.Println("✅ Delivered event published to Kafka!")
@@ -106,8 +122,17 @@ func newKafkaProducer() (sarama.SyncProducer, error) {
 	return sarama.NewSyncProducer([]string{kafkaBrokers}, config)
 }
 
-func publishMessage(producer sarama.SyncProducer, topic, key string, message interface{}) error {
-	jsonBytes, err := json.Marshal(message)
+func publishMessage(producer sarama.SyncProducer, topic, key, eventType string, message interface{}) error {
+	event := Event{
+		ID:          fmt.Sprintf("test-%d", time.Now().UnixNano()),
+		Type:        eventType,
+		Version:     1,
+		OccurredAt:  time.Now().UTC(),
+		AggregateID: key,
+		Producer:    "test_flow",
+		Data:        message,
+	}
+	jsonBytes, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
@@ -116,6 +141,11 @@ func publishMessage(producer sarama.SyncProducer, topic, key string, message int
 		Topic: topic,
 		Key:   sarama.StringEncoder(key),
 		Value: sarama.ByteEncoder(jsonBytes),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("x-event-id"), Value: []byte(event.ID)},
+			{Key: []byte("x-event-type"), Value: []byte(event.Type)},
+			{Key: []byte("x-event-version"), Value: []byte("1")},
+		},
 	}
 
 	partition, offset, err := producer.SendMessage(msg)
@@ -150,8 +180,11 @@ func createOrder() (string, error) {
 		return "", err
 	}
 	reqObj.Header.Set("Content-Type", "application/json")
-	reqObj.Header.Set("X-User-Id", "cust_123")
-	reqObj.Header.Set("X-User-Audience", "admin_portal")
+	token, err := devToken("cust_123", "admin_portal")
+	if err != nil {
+		return "", err
+	}
+	reqObj.Header.Set("Authorization", "Bearer "+token)
 
 	client := &http.Client{}
 	resp, err := client.Do(reqObj)
@@ -186,3 +219,29 @@ func createOrder() (string, error) {
 
 	return "", fmt.Errorf("could not parse order ID from: %s", string(body))
 }
+
+// devToken signs a short-lived token with the local dev key, as scripts/dev_token does
+func devToken(userID, role string) (string, error) {
+	data, err := os.ReadFile(devKeyFile)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", fmt.Errorf("no PEM block in %s", devKeyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"sub":  userID,
+		"role": role,
+		"iat":  now.Unix(),
+		"exp":  now.Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "go1-dev"
+	return token.SignedString(key)
+}